@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/port-experimental/port-go-sdk/pkg/catalog"
+)
+
+// loadOptions reads YAML definitions alongside JSON ones.
+var loadOptions = &catalog.LoadOptions{Decoders: map[string]catalog.Decoder{
+	".yaml": yaml.Unmarshal,
+	".yml":  yaml.Unmarshal,
+}}
+
+type applyFlags struct {
+	dir           string
+	envFile       string
+	managedBy     string
+	runID         string
+	prune         bool
+	allowBreaking bool
+	autoApprove   bool
+}
+
+func parseApplyFlags(name string, args []string) (applyFlags, error) {
+	var f applyFlags
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&f.dir, "f", "", "directory of JSON or YAML definition files")
+	fs.StringVar(&f.envFile, "env", ".env", "dotenv file with credentials")
+	fs.StringVar(&f.managedBy, "managed-by", "port-go-sdk", "label marking resources owned by this catalog")
+	fs.StringVar(&f.runID, "run-id", "", "run identifier recorded on changed resources (default: timestamp)")
+	fs.BoolVar(&f.prune, "prune", false, "delete managed resources that are no longer defined")
+	fs.BoolVar(&f.allowBreaking, "allow-breaking", false, "apply breaking blueprint changes")
+	fs.BoolVar(&f.autoApprove, "auto-approve", false, "skip the confirmation prompt")
+	if err := fs.Parse(args); err != nil {
+		return f, err
+	}
+	if f.dir == "" {
+		return f, fmt.Errorf("%s: -f is required", name)
+	}
+	return f, nil
+}
+
+func planFromFlags(ctx context.Context, f applyFlags) (*catalog.Engine, *catalog.Plan, error) {
+	resources, err := catalog.LoadDir(f.dir, loadOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	cli, err := newClient(f.envFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	eng := catalog.New(cli, &catalog.Options{
+		ManagedBy:     f.managedBy,
+		RunID:         f.runID,
+		Prune:         f.prune,
+		AllowBreaking: f.allowBreaking,
+	})
+	plan, err := eng.Plan(ctx, resources)
+	return eng, plan, err
+}
+
+func runPlan(ctx context.Context, args []string) error {
+	f, err := parseApplyFlags("plan", args)
+	if err != nil {
+		return err
+	}
+	_, plan, err := planFromFlags(ctx, f)
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan)
+	return nil
+}
+
+func runApply(ctx context.Context, args []string) error {
+	f, err := parseApplyFlags("apply", args)
+	if err != nil {
+		return err
+	}
+	eng, plan, err := planFromFlags(ctx, f)
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan)
+	if plan.Empty() {
+		fmt.Println("No changes. The organization matches the definitions.")
+		return nil
+	}
+	if !f.autoApprove && !confirm("Apply these changes?") {
+		return fmt.Errorf("apply cancelled")
+	}
+	if err := eng.Apply(ctx, plan); err != nil {
+		return err
+	}
+	fmt.Printf("Apply complete (run %s).\n", plan.RunID)
+	return nil
+}
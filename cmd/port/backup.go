@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/backup"
+)
+
+func runBackup(ctx context.Context, args []string) error {
+	var out, envFile, only string
+	var skipEntities bool
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.StringVar(&out, "o", "", "output directory, or a .tar.gz file")
+	fs.StringVar(&envFile, "env", ".env", "dotenv file with credentials")
+	fs.StringVar(&only, "blueprints", "", "comma-separated blueprints to back up (default: whole organization)")
+	fs.BoolVar(&skipEntities, "skip-entities", false, "back up definitions only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if out == "" {
+		return fmt.Errorf("backup: -o is required")
+	}
+	cli, err := newClient(envFile)
+	if err != nil {
+		return err
+	}
+	opts := &backup.Options{Blueprints: splitList(only), SkipEntities: skipEntities}
+
+	// Directories get a timestamped snapshot per run; archives are built in a
+	// temporary directory first.
+	dir := filepath.Join(out, time.Now().UTC().Format("20060102T150405Z"))
+	if backup.IsArchive(out) {
+		tmp, err := os.MkdirTemp("", "port-backup-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir = filepath.Join(tmp, "snapshot")
+	}
+	m, err := backup.New(cli).Backup(ctx, dir, opts)
+	if err != nil {
+		return err
+	}
+	if backup.IsArchive(out) {
+		f, err := os.OpenFile(out, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		if err := backup.WriteArchive(f, dir); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		dir = out
+	}
+	fmt.Printf("Backup written to %s (%s).\n", dir, summarize(m.Counts))
+	return nil
+}
+
+func runRestore(ctx context.Context, args []string) error {
+	var in, envFile, only string
+	var skipEntities, autoApprove bool
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.StringVar(&in, "i", "", "snapshot directory, or a .tar.gz file")
+	fs.StringVar(&envFile, "env", ".env", "dotenv file with credentials of the target organization")
+	fs.StringVar(&only, "blueprints", "", "comma-separated blueprints to restore (default: everything)")
+	fs.BoolVar(&skipEntities, "skip-entities", false, "restore definitions only")
+	fs.BoolVar(&autoApprove, "auto-approve", false, "skip the confirmation prompt")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if in == "" {
+		return fmt.Errorf("restore: -i is required")
+	}
+	dir := in
+	if backup.IsArchive(in) {
+		tmp, err := os.MkdirTemp("", "port-restore-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		f, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		err = backup.ExtractArchive(f, tmp)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		dir = tmp
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return fmt.Errorf("restore: %s is not a snapshot: %w", in, err)
+	}
+	var m backup.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("restore: manifest: %w", err)
+	}
+	fmt.Printf("Snapshot taken %s with SDK %s: %s.\n", m.CreatedAt.Format(time.RFC3339), m.SDKVersion, summarize(m.Counts))
+	if !autoApprove && !confirm("Restore this snapshot? Existing resources will be overwritten.") {
+		return fmt.Errorf("restore cancelled")
+	}
+	cli, err := newClient(envFile)
+	if err != nil {
+		return err
+	}
+	report, err := backup.New(cli).Restore(ctx, os.DirFS(dir), &backup.RestoreOptions{
+		Blueprints:   splitList(only),
+		SkipEntities: skipEntities,
+	})
+	if report != nil {
+		for _, s := range report.Skipped {
+			fmt.Printf("  skipped %s\n", s)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restore complete (%s).\n", summarize(report.Counts))
+	return nil
+}
+
+// summarize formats resource counts as "2 blueprints, 10 entities".
+func summarize(counts map[string]int) string {
+	kinds := make([]string, 0, len(counts))
+	for k := range counts {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	s := ""
+	for _, k := range kinds {
+		if s != "" {
+			s += ", "
+		}
+		s += fmt.Sprintf("%d %s", counts[k], k)
+	}
+	if s == "" {
+		return "empty"
+	}
+	return s
+}
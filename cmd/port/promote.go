@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/port-experimental/port-go-sdk/pkg/catalog"
+)
+
+// listFlag collects a repeatable string flag.
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, ",") }
+func (l *listFlag) Set(v string) error { *l = append(*l, v); return nil }
+
+func runPromote(ctx context.Context, args []string) error {
+	var (
+		from, to, kinds, only, managedBy string
+		remaps, secrets                  listFlag
+		diffOnly, autoApprove, breaking  bool
+	)
+	fs := flag.NewFlagSet("promote", flag.ContinueOnError)
+	fs.StringVar(&from, "from", "", "dotenv file with source organization credentials")
+	fs.StringVar(&to, "to", "", "dotenv file with target organization credentials")
+	fs.StringVar(&kinds, "kinds", "", "comma-separated kinds to promote (default: blueprint,scorecard,action,webhook,page)")
+	fs.StringVar(&only, "only", "", "comma-separated source identifiers to promote, <blueprint>/<id> for scorecards (default: all)")
+	fs.StringVar(&managedBy, "managed-by", "port-go-sdk", "label marking resources written in the target")
+	fs.Var(&remaps, "remap", "identifier rewrite [kind:]regexp=replacement, repeatable")
+	fs.Var(&secrets, "secret", "secret placeholder value name=value, repeatable")
+	fs.BoolVar(&diffOnly, "diff", false, "only print the differences")
+	fs.BoolVar(&autoApprove, "auto-approve", false, "skip the confirmation prompt")
+	fs.BoolVar(&breaking, "allow-breaking", false, "apply breaking blueprint changes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if from == "" || to == "" {
+		return fmt.Errorf("promote: -from and -to are required")
+	}
+
+	opts := &catalog.PromoteOptions{Secrets: map[string]string{}}
+	for _, k := range splitList(kinds) {
+		opts.Kinds = append(opts.Kinds, catalog.Kind(k))
+	}
+	if ids := splitList(only); len(ids) > 0 {
+		selected := map[string]bool{}
+		for _, id := range ids {
+			selected[id] = true
+		}
+		opts.Select = func(r catalog.Resource) bool { return selected[r.ID] }
+	}
+	for _, spec := range remaps {
+		rule, err := parseRemap(spec)
+		if err != nil {
+			return err
+		}
+		opts.Remap = append(opts.Remap, rule)
+	}
+	for _, kv := range secrets {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("promote: -secret %q must be name=value", kv)
+		}
+		opts.Secrets[name] = value
+	}
+
+	srcClient, err := newClientFromFile(from)
+	if err != nil {
+		return err
+	}
+	dstClient, err := newClientFromFile(to)
+	if err != nil {
+		return err
+	}
+	target := catalog.New(dstClient, &catalog.Options{ManagedBy: managedBy, AllowBreaking: breaking})
+	promo, err := catalog.Promote(ctx, catalog.New(srcClient, nil), target, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Print(promo.Plan)
+	for _, name := range promo.MissingSecrets {
+		fmt.Printf("warning: secret %s exists in the source but not in the target\n", name)
+	}
+	for _, name := range promo.UnresolvedSecrets {
+		fmt.Printf("warning: no value for %s; the target keeps its current secret\n", name)
+	}
+	if diffOnly || promo.Plan.Empty() {
+		return nil
+	}
+	if !autoApprove && !confirm("Promote these changes?") {
+		return fmt.Errorf("promote cancelled")
+	}
+	if err := target.Apply(ctx, promo.Plan); err != nil {
+		return err
+	}
+	fmt.Printf("Promotion complete (run %s).\n", promo.Plan.RunID)
+	return nil
+}
+
+// parseRemap parses "[kind:]regexp=replacement".
+func parseRemap(spec string) (catalog.RemapRule, error) {
+	var rule catalog.RemapRule
+	pattern, replace, ok := strings.Cut(spec, "=")
+	if !ok {
+		return rule, fmt.Errorf("promote: -remap %q must be [kind:]regexp=replacement", spec)
+	}
+	if kind, rest, found := strings.Cut(pattern, ":"); found && !strings.ContainsAny(kind, `^$()[]\.*+?`) {
+		rule.Kind, pattern = catalog.Kind(kind), rest
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return rule, fmt.Errorf("promote: -remap %q: %w", spec, err)
+	}
+	rule.Match, rule.Replace = re, replace
+	return rule, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
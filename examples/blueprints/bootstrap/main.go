@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/blueprints"
+	"github.com/port-experimental/port-go-sdk/pkg/client"
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+)
+
+func main() {
+	teardown := flag.Bool("teardown", false, "delete the example blueprints and their entities")
+	dryRun := flag.Bool("dry-run", false, "print the plan without calling Port")
+	flag.Parse()
+
+	plan, err := blueprints.NewPlan([]blueprints.Blueprint{
+		{
+			Identifier: "example_service",
+			Title:      "Example Service",
+			Schema:     map[string]any{"properties": map[string]any{}},
+			Relations: map[string]blueprints.Relation{
+				"team":      {Title: "Team", Target: "example_team"},
+				"dependsOn": {Title: "Depends On", Target: "example_service", Many: true},
+			},
+		},
+		{
+			Identifier: "example_team",
+			Title:      "Example Team",
+			Schema:     map[string]any{"properties": map[string]any{}},
+			Relations: map[string]blueprints.Relation{
+				"lead": {Title: "Lead Service", Target: "example_service"},
+			},
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	steps := plan.Steps()
+	if *teardown {
+		steps = plan.TeardownSteps()
+	}
+	for _, step := range steps {
+		fmt.Println(step)
+	}
+	if *dryRun {
+		return
+	}
+
+	cfg, err := config.Load(".env")
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiClient, err := client.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if *teardown {
+		err = apiClient.Blueprints().Teardown(ctx, plan)
+	} else {
+		err = apiClient.Blueprints().Apply(ctx, plan)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("done")
+}
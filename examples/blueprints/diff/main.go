@@ -0,0 +1,62 @@
+// Command diff compares a blueprint definition on disk with the live one and
+// exits non-zero on breaking changes, for use as a CI gate on schema PRs:
+//
+//	go run ./examples/blueprints/diff -f blueprints/service.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/blueprints"
+	"github.com/port-experimental/port-go-sdk/pkg/client"
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/porter"
+)
+
+func main() {
+	file := flag.String("f", "", "blueprint JSON definition")
+	allowRisky := flag.Bool("allow-risky", true, "only fail on breaking changes")
+	flag.Parse()
+	if *file == "" {
+		log.Fatal("-f is required")
+	}
+	raw, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var desired blueprints.Blueprint
+	if err := json.Unmarshal(raw, &desired); err != nil {
+		log.Fatalf("parse %s: %v", *file, err)
+	}
+
+	cfg, err := config.Load(".env")
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiClient, err := client.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	current, err := apiClient.Blueprints().Get(ctx, desired.Identifier)
+	if err != nil && !porter.IsNotFound(err) {
+		log.Fatal(err)
+	}
+
+	diff := blueprints.Diff(current, desired)
+	fmt.Print(diff)
+	limit := blueprints.SeverityRisky
+	if *allowRisky {
+		limit = blueprints.SeverityBreaking
+	}
+	if len(diff.AtLeast(limit)) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/client"
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/entities"
+)
+
+func main() {
+	cfg, err := config.Load(".env")
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiClient, err := client.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = apiClient.Entities().Watch(ctx, "example_blueprint", nil, entities.WatchOptions{
+		Interval: 15 * time.Second,
+		Jitter:   5 * time.Second,
+		Handler: func(_ context.Context, ev entities.Event) error {
+			switch ev.Type {
+			case entities.EventUpdated:
+				fmt.Printf("%s %s properties=%v relations=%v\n", ev.Type, ev.Entity.Identifier, ev.Diff.Properties, ev.Diff.Relations)
+			default:
+				fmt.Printf("%s %s\n", ev.Type, ev.Entity.Identifier)
+			}
+			return nil
+		},
+		OnError: func(err error) { log.Printf("watch poll failed: %v", err) },
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+)
+
+// TokenCacheOptions configure a CachingTokenSource. Zero fields use the
+// defaults noted below.
+type TokenCacheOptions struct {
+	// Store persists tokens. Defaults to a FileTokenStore in the user cache
+	// directory.
+	Store TokenStore
+	// ExpiryMargin is how long before expiry a stored token stops being
+	// reused. Defaults to 1m.
+	ExpiryMargin time.Duration
+	// EncryptionKey, if set, encrypts stored tokens with AES-GCM. It must be
+	// 16, 24 or 32 bytes. Entries that cannot be decrypted with it, for
+	// example after a key change, are treated as missing.
+	EncryptionKey []byte
+}
+
+// CachingTokenSource is a client credentials token source that shares tokens
+// through a TokenStore, keyed by API base URL and client ID, so CLI
+// invocations and short-lived jobs reuse a token instead of each exchanging
+// credentials. It implements InvalidatingTokenSource and TokenInfoProvider.
+type CachingTokenSource struct {
+	creds  *ClientCredentialsSource
+	store  TokenStore
+	key    string
+	margin time.Duration
+	aead   cipher.AEAD
+
+	// sem serializes cache misses within the process; unlike a mutex,
+	// waiting on it honors the caller's context.
+	sem chan struct{}
+
+	mu        sync.Mutex
+	token     string
+	expires   time.Time
+	refreshed time.Time
+	revoked   string
+	lastErr   error
+}
+
+// storedToken is the persisted form of a token.
+type storedToken struct {
+	AccessToken string    `json:"accessToken"`
+	Expiry      time.Time `json:"expiry"`
+	Refreshed   time.Time `json:"refreshed"`
+}
+
+// NewCachingTokenSource returns a caching source for cfg's client
+// credentials. A nil hc uses httpx.New().
+func NewCachingTokenSource(cfg config.Config, hc httpx.Doer, opts TokenCacheOptions) (*CachingTokenSource, error) {
+	if opts.Store == nil {
+		store, err := NewFileTokenStore("")
+		if err != nil {
+			return nil, err
+		}
+		opts.Store = store
+	}
+	if opts.ExpiryMargin <= 0 {
+		opts.ExpiryMargin = time.Minute
+	}
+	c := &CachingTokenSource{
+		creds:  NewClientCredentialsSource(cfg, hc, ClientCredentialsOptions{}),
+		store:  opts.Store,
+		key:    cfg.BaseEndpoint() + "|" + cfg.ClientID,
+		margin: opts.ExpiryMargin,
+		sem:    make(chan struct{}, 1),
+	}
+	if len(opts.EncryptionKey) > 0 {
+		block, err := aes.NewCipher(opts.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("port auth: token cache key: %w", err)
+		}
+		if c.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, fmt.Errorf("port auth: token cache key: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// Token implements TokenSource. It returns the token held in memory or the
+// store while it is valid for longer than the expiry margin, and otherwise
+// exchanges credentials and saves the new token. Store errors are reported
+// through TokenInfo rather than failing the call.
+func (c *CachingTokenSource) Token(ctx context.Context) (string, error) {
+	if token, ok := c.cached(); ok {
+		return token, nil
+	}
+	select {
+	case c.sem <- struct{}{}:
+		defer func() { <-c.sem }()
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	// Another goroutine may have filled the cache while we waited.
+	if token, ok := c.cached(); ok {
+		return token, nil
+	}
+	var storeErr error
+	if locker, ok := c.store.(TokenStoreLocker); ok {
+		unlock, err := locker.Lock(ctx, c.key)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", err
+			}
+			// Fall back to an unshared exchange rather than failing.
+			storeErr = err
+		} else {
+			defer unlock()
+		}
+	}
+	if st, err := c.load(ctx); err != nil {
+		storeErr = errors.Join(storeErr, err)
+	} else if st != nil {
+		c.set(*st, storeErr)
+		return st.AccessToken, nil
+	}
+	token, expires, err := c.creds.exchange(ctx)
+	if err != nil {
+		c.mu.Lock()
+		c.lastErr = err
+		c.mu.Unlock()
+		return "", err
+	}
+	st := storedToken{AccessToken: token, Expiry: expires, Refreshed: time.Now()}
+	if err := c.save(ctx, st); err != nil {
+		storeErr = errors.Join(storeErr, err)
+	}
+	c.set(st, storeErr)
+	return token, nil
+}
+
+// Invalidate implements InvalidatingTokenSource. The token is also ignored
+// if found in the store, so the next Token call exchanges credentials and
+// replaces it there for other processes.
+func (c *CachingTokenSource) Invalidate(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.revoked = token
+	if token == c.token {
+		c.token = ""
+		c.expires = time.Time{}
+	}
+}
+
+// TokenInfo implements TokenInfoProvider. LastError also reports failures to
+// read or write the store.
+func (c *CachingTokenSource) TokenInfo() TokenInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return TokenInfo{
+		Valid:     c.token != "" && time.Now().Before(c.expires),
+		Expiry:    c.expires,
+		Refreshed: c.refreshed,
+		LastError: c.lastErr,
+	}
+}
+
+func (c *CachingTokenSource) cached() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Until(c.expires) > c.margin {
+		return c.token, true
+	}
+	return "", false
+}
+
+func (c *CachingTokenSource) set(st storedToken, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token, c.expires, c.refreshed, c.lastErr = st.AccessToken, st.Expiry, st.Refreshed, err
+}
+
+// load returns the stored token if it is usable. Entries that are expired,
+// revoked or cannot be decoded count as missing.
+func (c *CachingTokenSource) load(ctx context.Context) (*storedToken, error) {
+	data, err := c.store.Load(ctx, c.key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	if c.aead != nil {
+		n := c.aead.NonceSize()
+		if len(data) < n {
+			return nil, nil
+		}
+		if data, err = c.aead.Open(nil, data[:n], data[n:], []byte(c.key)); err != nil {
+			return nil, nil
+		}
+	}
+	var st storedToken
+	if err := json.Unmarshal(data, &st); err != nil || st.AccessToken == "" {
+		return nil, nil
+	}
+	c.mu.Lock()
+	revoked := st.AccessToken == c.revoked
+	c.mu.Unlock()
+	if revoked || time.Until(st.Expiry) <= c.margin {
+		return nil, nil
+	}
+	return &st, nil
+}
+
+func (c *CachingTokenSource) save(ctx context.Context, st storedToken) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("port auth: encode token cache: %w", err)
+	}
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("port auth: encrypt token cache: %w", err)
+		}
+		// The cache key is authenticated, so an entry copied to another
+		// key's file does not decrypt.
+		data = c.aead.Seal(nonce, nonce, data, []byte(c.key))
+	}
+	return c.store.Save(ctx, c.key, data)
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+)
+
+func TestCachingTokenSource(t *testing.T) {
+	var exchanges int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&exchanges, 1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"accessToken": fmt.Sprintf("token-%d", n), "expiresIn": 3600})
+	}))
+	defer srv.Close()
+	cfg := config.Config{ClientID: "id", ClientSecret: "secret", BaseURL: srv.URL}
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{7}, 32)
+
+	// Each source stands in for a separate process sharing the cache dir.
+	newSource := func(key []byte) *CachingTokenSource {
+		store, err := NewFileTokenStore(dir)
+		if err != nil {
+			t.Fatalf("store: %v", err)
+		}
+		src, err := NewCachingTokenSource(cfg, httpx.New(), TokenCacheOptions{Store: store, EncryptionKey: key})
+		if err != nil {
+			t.Fatalf("source: %v", err)
+		}
+		return src
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tok, err := newSource(key).Token(context.Background()); err != nil || tok != "token-1" {
+				t.Errorf("token %q err %v", tok, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&exchanges); n != 1 {
+		t.Fatalf("expected one shared exchange, got %d", n)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected one cache file, got %v", files)
+	}
+	fi, err := os.Stat(files[0])
+	if err != nil || fi.Mode().Perm() != 0o600 {
+		t.Fatalf("cache file mode %v err %v", fi.Mode(), err)
+	}
+	if data, _ := os.ReadFile(files[0]); bytes.Contains(data, []byte("token-1")) {
+		t.Fatal("token stored in plain text despite encryption key")
+	}
+
+	// A different key cannot read the entry and replaces it.
+	if tok, _ := newSource(bytes.Repeat([]byte{9}, 32)).Token(context.Background()); tok != "token-2" {
+		t.Fatalf("expected new token with other key, got %q", tok)
+	}
+
+	// An invalidated token is not reused from the store.
+	src := newSource(nil)
+	if tok, _ := src.Token(context.Background()); tok != "token-3" {
+		t.Fatalf("expected new token without key, got %q", tok)
+	}
+	src.Invalidate("token-3")
+	if tok, _ := src.Token(context.Background()); tok != "token-4" {
+		t.Fatalf("expected fresh token after invalidate, got %q", tok)
+	}
+	if tok, _ := newSource(nil).Token(context.Background()); tok != "token-4" {
+		t.Fatalf("expected stored token, got %q", tok)
+	}
+	if info := src.TokenInfo(); !info.Valid || info.LastError != nil {
+		t.Fatalf("info %+v", info)
+	}
+
+	if _, err := NewCachingTokenSource(cfg, nil, TokenCacheOptions{Store: &FileTokenStore{dir: dir}, EncryptionKey: []byte("short")}); err == nil {
+		t.Fatal("expected invalid key error")
+	}
+}
+
+func TestCachingTokenSourceExpiryMargin(t *testing.T) {
+	var exchanges int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&exchanges, 1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"accessToken": "short", "expiresIn": 30})
+	}))
+	defer srv.Close()
+	cfg := config.Config{ClientID: "id", ClientSecret: "secret", BaseURL: srv.URL}
+	store, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		src, err := NewCachingTokenSource(cfg, nil, TokenCacheOptions{Store: store})
+		if err != nil {
+			t.Fatalf("source: %v", err)
+		}
+		if _, err := src.Token(context.Background()); err != nil {
+			t.Fatalf("token: %v", err)
+		}
+	}
+	// Tokens expiring within the default 1m margin are not reused.
+	if n := atomic.LoadInt32(&exchanges); n != 2 {
+		t.Fatalf("expected 2 exchanges, got %d", n)
+	}
+}
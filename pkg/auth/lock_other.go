@@ -0,0 +1,30 @@
+//go:build !unix
+
+package auth
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// staleLockAge is how old a lock file must be before it is treated as left
+// behind by a process that died while holding it.
+const staleLockAge = time.Minute
+
+// tryLock creates path exclusively as a lock file. It returns a nil unlock
+// func if another process holds the lock.
+func tryLock(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		if fi, statErr := os.Stat(path); statErr == nil && time.Since(fi.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return func() { _ = os.Remove(path) }, nil
+}
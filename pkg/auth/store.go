@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TokenStore persists tokens for a CachingTokenSource, so processes can reuse
+// a token instead of each exchanging credentials. Data is opaque and may be
+// encrypted.
+type TokenStore interface {
+	// Load returns the data saved under key, or nil if there is none.
+	Load(ctx context.Context, key string) ([]byte, error)
+	Save(ctx context.Context, key string, data []byte) error
+}
+
+// TokenStoreLocker is implemented by stores shared between processes. A
+// CachingTokenSource holds the lock for key while it checks the store and,
+// on a miss, exchanges credentials, so concurrent processes share one
+// exchange.
+type TokenStoreLocker interface {
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// FileTokenStore keeps tokens in files readable only by the current user,
+// one per key, and locks them across processes. It implements TokenStore
+// and TokenStoreLocker.
+type FileTokenStore struct {
+	dir string
+}
+
+// NewFileTokenStore returns a store in dir. An empty dir uses port-go-sdk/tokens
+// under the user cache directory (os.UserCacheDir).
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("port auth: token cache dir: %w", err)
+		}
+		dir = filepath.Join(base, "port-go-sdk", "tokens")
+	}
+	return &FileTokenStore{dir: dir}, nil
+}
+
+// lockPollInterval is how often Lock retries a lock held by another process.
+const lockPollInterval = 50 * time.Millisecond
+
+// path returns the file for key. Keys are hashed, so client IDs do not
+// appear in file names.
+func (s *FileTokenStore) path(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+ext)
+}
+
+// Load implements TokenStore.
+func (s *FileTokenStore) Load(_ context.Context, key string) ([]byte, error) {
+	b, err := os.ReadFile(s.path(key, ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("port auth: read token cache: %w", err)
+	}
+	return b, nil
+}
+
+// Save implements TokenStore. The file is replaced atomically, so readers
+// that do not lock never see a partial write.
+func (s *FileTokenStore) Save(_ context.Context, key string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("port auth: create token cache dir: %w", err)
+	}
+	f, err := os.CreateTemp(s.dir, ".token-*")
+	if err != nil {
+		return fmt.Errorf("port auth: write token cache: %w", err)
+	}
+	// CreateTemp creates the file with mode 0600.
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("port auth: write token cache: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("port auth: write token cache: %w", err)
+	}
+	if err := os.Rename(f.Name(), s.path(key, ".json")); err != nil {
+		return fmt.Errorf("port auth: write token cache: %w", err)
+	}
+	return nil
+}
+
+// Lock implements TokenStoreLocker, waiting until the lock for key is free
+// or ctx is done.
+func (s *FileTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("port auth: create token cache dir: %w", err)
+	}
+	path := s.path(key, ".lock")
+	for {
+		unlock, err := tryLock(path)
+		if err != nil {
+			return nil, fmt.Errorf("port auth: lock token cache: %w", err)
+		}
+		if unlock != nil {
+			return unlock, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
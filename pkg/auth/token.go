@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -19,75 +20,276 @@ type TokenSource interface {
 	Token(ctx context.Context) (string, error)
 }
 
+// InvalidatingTokenSource is a TokenSource whose cached token can be dropped,
+// for example after the API rejects it with 401 because it was revoked.
+type InvalidatingTokenSource interface {
+	TokenSource
+	// Invalidate drops token if it is still the cached token, so the next
+	// Token call fetches a new one. Tokens already replaced are ignored,
+	// so concurrent callers do not discard a fresh token.
+	Invalidate(token string)
+}
+
+// TokenInfoProvider is implemented by token sources that can report on
+// their current token.
+type TokenInfoProvider interface {
+	TokenInfo() TokenInfo
+}
+
+// TokenInfo describes the token held by a source.
+type TokenInfo struct {
+	// Valid reports whether a token is held and has not expired.
+	Valid bool
+	// Expiry is when the token expires; zero for tokens without a known
+	// expiry, such as personal API tokens.
+	Expiry time.Time
+	// Refreshed is when the token was last obtained.
+	Refreshed time.Time
+	// LastError is the error of the last failed refresh, cleared by the
+	// next successful one.
+	LastError error
+}
+
 // NewTokenSource chooses between static token or client credentials flow.
+// Client credentials sources implement InvalidatingTokenSource.
 func NewTokenSource(cfg config.Config, hc httpx.Doer) TokenSource {
 	if cfg.APIToken != "" {
 		return &staticToken{token: cfg.APIToken}
 	}
+	return NewClientCredentialsSource(cfg, hc, ClientCredentialsOptions{})
+}
+
+type staticToken struct {
+	token string
+}
+
+func (s *staticToken) Token(context.Context) (string, error) {
+	return s.token, nil
+}
+
+func (s *staticToken) TokenInfo() TokenInfo {
+	return TokenInfo{Valid: true}
+}
+
+// ClientCredentialsOptions configure a ClientCredentialsSource. Zero fields
+// use the defaults noted below.
+type ClientCredentialsOptions struct {
+	// ExpiryMargin is how long before expiry a token stops being used and
+	// is refreshed on the request path. Defaults to 30s.
+	ExpiryMargin time.Duration
+	// RefreshFraction enables background refresh: once this fraction of a
+	// token's lifetime has passed (for example 0.75), a new token is
+	// fetched off the request path. Zero disables background refresh.
+	RefreshFraction float64
+	// RefreshJitter spreads background refreshes by up to this fraction of
+	// the lifetime either way, so many clients do not refresh at once.
+	// Defaults to 0.05.
+	RefreshJitter float64
+	// MinBackoff and MaxBackoff bound the exponential wait between
+	// background refresh attempts after a failure. Default to 1s and 1m.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// refreshTimeout bounds a shared token exchange, which outlives the context
+// of the caller that started it.
+const refreshTimeout = 30 * time.Second
+
+// ClientCredentialsSource exchanges a client ID and secret for access tokens
+// and caches them. Concurrent callers share one exchange. It implements
+// InvalidatingTokenSource and TokenInfoProvider; call Close to stop
+// background refresh.
+type ClientCredentialsSource struct {
+	cfg  config.Config
+	hc   httpx.Doer
+	opts ClientCredentialsOptions
+
+	mu        sync.Mutex
+	token     string
+	expires   time.Time
+	refreshed time.Time
+	lastErr   error
+	failures  int
+	inflight  *refreshCall
+	timer     *time.Timer
+	closed    bool
+}
+
+// NewClientCredentialsSource returns a source for cfg's client credentials.
+// A nil hc uses httpx.New().
+func NewClientCredentialsSource(cfg config.Config, hc httpx.Doer, opts ClientCredentialsOptions) *ClientCredentialsSource {
 	if hc == nil {
 		hc = httpx.New()
 	}
-	return &clientCredsSource{
-		cfg: cfg,
-		hc:  hc,
+	if opts.ExpiryMargin <= 0 {
+		opts.ExpiryMargin = 30 * time.Second
+	}
+	if opts.RefreshJitter <= 0 {
+		opts.RefreshJitter = 0.05
 	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+	return &ClientCredentialsSource{cfg: cfg, hc: hc, opts: opts}
 }
 
-type staticToken struct {
+// refreshCall is a token exchange shared by every caller that needs a token
+// while it runs.
+type refreshCall struct {
+	done  chan struct{}
 	token string
+	err   error
 }
 
-func (s *staticToken) Token(context.Context) (string, error) {
-	return s.token, nil
+// Token implements TokenSource.
+func (c *ClientCredentialsSource) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	if c.token != "" && time.Until(c.expires) > c.opts.ExpiryMargin {
+		token := c.token
+		c.mu.Unlock()
+		return token, nil
+	}
+	call := c.startRefreshLocked(ctx)
+	c.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
 }
 
-type clientCredsSource struct {
-	cfg config.Config
-	hc  httpx.Doer
+// Invalidate implements InvalidatingTokenSource.
+func (c *ClientCredentialsSource) Invalidate(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if token == c.token {
+		c.token = ""
+		c.expires = time.Time{}
+	}
+}
 
-	mu      sync.Mutex
-	token   string
-	expires time.Time
+// TokenInfo implements TokenInfoProvider.
+func (c *ClientCredentialsSource) TokenInfo() TokenInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return TokenInfo{
+		Valid:     c.token != "" && time.Now().Before(c.expires),
+		Expiry:    c.expires,
+		Refreshed: c.refreshed,
+		LastError: c.lastErr,
+	}
 }
 
-func (c *clientCredsSource) Token(ctx context.Context) (string, error) {
+// Close stops background refresh. The source still fetches tokens on
+// demand afterwards.
+func (c *ClientCredentialsSource) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.token != "" && time.Until(c.expires) > 30*time.Second {
-		return c.token, nil
+	c.closed = true
+	if c.timer != nil {
+		c.timer.Stop()
 	}
-	if err := c.refresh(ctx); err != nil {
-		return "", err
+	return nil
+}
+
+// startRefreshLocked joins the running exchange or starts one.
+func (c *ClientCredentialsSource) startRefreshLocked(ctx context.Context) *refreshCall {
+	if c.inflight != nil {
+		return c.inflight
 	}
-	return c.token, nil
+	call := &refreshCall{done: make(chan struct{})}
+	c.inflight = call
+	go c.refresh(context.WithoutCancel(ctx), call)
+	return call
 }
 
-func (c *clientCredsSource) refresh(ctx context.Context) error {
+// refresh runs call without holding the lock, so callers with a valid token
+// are never blocked behind the network.
+func (c *ClientCredentialsSource) refresh(ctx context.Context, call *refreshCall) {
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+	token, expires, err := c.exchange(ctx)
+	c.mu.Lock()
+	if err == nil {
+		c.token, c.expires, c.refreshed = token, expires, time.Now()
+		c.lastErr, c.failures = nil, 0
+	} else {
+		c.lastErr = err
+		c.failures++
+	}
+	c.inflight = nil
+	c.scheduleLocked()
+	c.mu.Unlock()
+	call.token, call.err = token, err
+	close(call.done)
+}
+
+// scheduleLocked arms the background refresh: at RefreshFraction of the
+// token lifetime with jitter, or after an exponential backoff following
+// failures.
+func (c *ClientCredentialsSource) scheduleLocked() {
+	if c.opts.RefreshFraction <= 0 || c.closed {
+		return
+	}
+	var wait time.Duration
+	if c.failures > 0 {
+		wait = c.opts.MinBackoff << (c.failures - 1)
+		if wait > c.opts.MaxBackoff || wait <= 0 {
+			wait = c.opts.MaxBackoff
+		}
+	} else {
+		lifetime := c.expires.Sub(c.refreshed)
+		//nolint:gosec // G404: math/rand is acceptable for non-cryptographic jitter
+		jitter := (rand.Float64()*2 - 1) * c.opts.RefreshJitter
+		wait = time.Duration(float64(lifetime) * (c.opts.RefreshFraction + jitter))
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(wait, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if !c.closed {
+			c.startRefreshLocked(context.Background())
+		}
+	})
+}
+
+// exchange trades the client credentials for a token.
+func (c *ClientCredentialsSource) exchange(ctx context.Context) (string, time.Time, error) {
 	payload := map[string]string{
 		"clientId":     c.cfg.ClientID,
 		"clientSecret": c.cfg.ClientSecret,
 	}
 	b, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("port auth: failed to marshal request: %w", err)
+		return "", time.Time{}, fmt.Errorf("port auth: failed to marshal request: %w", err)
 	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseEndpoint()+"/v1/auth/access_token", bytes.NewReader(b))
 	if err != nil {
-		return fmt.Errorf("port auth: failed to create request: %w", err)
+		return "", time.Time{}, fmt.Errorf("port auth: failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	httpx.SetUserAgent(req, "")
-	resp, err := httpx.DoWithRetry(ctx, c.hc, req, 3)
+	// Exchanging credentials has no side effects, so it is safe to retry
+	// even though it is a POST.
+	policy := httpx.DefaultRetryPolicy(3)
+	policy.RetryNonIdempotent = true
+	resp, err := httpx.DoWithPolicy(ctx, c.hc, req, policy)
 	if err != nil {
-		return fmt.Errorf("port auth: %w", err)
+		return "", time.Time{}, fmt.Errorf("port auth: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
 		body, readErr := io.ReadAll(resp.Body)
 		if readErr != nil {
-			return fmt.Errorf("port auth failed: %s (failed to read error body: %v)", resp.Status, readErr)
+			return "", time.Time{}, fmt.Errorf("port auth failed: %s (failed to read error body: %v)", resp.Status, readErr)
 		}
-		return fmt.Errorf("port auth failed: %s %s", resp.Status, bytes.TrimSpace(body))
+		return "", time.Time{}, fmt.Errorf("port auth failed: %s %s", resp.Status, bytes.TrimSpace(body))
 	}
 	var out struct {
 		AccessToken string `json:"accessToken"`
@@ -95,15 +297,13 @@ func (c *clientCredsSource) refresh(ctx context.Context) error {
 	}
 	var raw bytes.Buffer
 	if err := json.NewDecoder(io.TeeReader(resp.Body, &raw)).Decode(&out); err != nil {
-		return err
+		return "", time.Time{}, fmt.Errorf("port auth: decode token response: %w", err)
 	}
 	if out.AccessToken == "" {
-		return fmt.Errorf("port auth: empty token response=%s", raw.String())
+		return "", time.Time{}, fmt.Errorf("port auth: empty token response=%s", raw.String())
 	}
-	c.token = out.AccessToken
 	if out.ExpiresIn == 0 {
 		out.ExpiresIn = 3600
 	}
-	c.expires = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
-	return nil
+	return out.AccessToken, time.Now().Add(time.Duration(out.ExpiresIn) * time.Second), nil
 }
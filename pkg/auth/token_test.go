@@ -3,8 +3,11 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -47,3 +50,86 @@ func TestClientCredentialsSource(t *testing.T) {
 		t.Fatalf("expected refresh success: %s err %v", tok, err)
 	}
 }
+
+func TestClientCredentialsSingleflight(t *testing.T) {
+	var exchanges int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&exchanges, 1)
+		<-release
+		_ = json.NewEncoder(w).Encode(map[string]any{"accessToken": fmt.Sprintf("t%d", n), "expiresIn": 3600})
+	}))
+	defer srv.Close()
+	src := NewTokenSource(config.Config{ClientID: "id", ClientSecret: "secret", BaseURL: srv.URL}, httpx.New())
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = src.Token(context.Background())
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	for _, tok := range tokens {
+		if tok != "t1" {
+			t.Fatalf("tokens %v", tokens)
+		}
+	}
+	if n := atomic.LoadInt32(&exchanges); n != 1 {
+		t.Fatalf("expected one exchange, got %d", n)
+	}
+
+	inv := src.(InvalidatingTokenSource)
+	inv.Invalidate("stale")
+	if tok, _ := src.Token(context.Background()); tok != "t1" {
+		t.Fatalf("stale invalidation dropped the token: %s", tok)
+	}
+	inv.Invalidate("t1")
+	if tok, _ := src.Token(context.Background()); tok != "t2" {
+		t.Fatalf("expected refreshed token, got %s", tok)
+	}
+}
+
+func TestClientCredentialsBackgroundRefresh(t *testing.T) {
+	var exchanges int32
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n := atomic.AddInt32(&exchanges, 1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"accessToken": fmt.Sprintf("t%d", n), "expiresIn": 1})
+	}))
+	defer srv.Close()
+	src := NewClientCredentialsSource(config.Config{ClientID: "id", ClientSecret: "secret", BaseURL: srv.URL}, httpx.New(),
+		ClientCredentialsOptions{ExpiryMargin: time.Millisecond, RefreshFraction: 0.2, MinBackoff: 10 * time.Millisecond})
+	defer src.Close()
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&exchanges) >= 3 })
+	if info := src.TokenInfo(); !info.Valid || info.LastError != nil {
+		t.Fatalf("info %+v", info)
+	}
+
+	failing.Store(true)
+	waitFor(t, func() bool { return src.TokenInfo().LastError != nil })
+	failing.Store(false)
+	waitFor(t, func() bool { return src.TokenInfo().LastError == nil })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -192,14 +192,38 @@ func decodeExecutions(raw json.RawMessage) ([]Execution, error) {
 
 // ListDefinitions returns full action/automation definitions (including triggers).
 func (s *Service) ListDefinitions(ctx context.Context) ([]ActionDefinition, error) {
+	return s.ListActionDefinitions(ctx, "automation")
+}
+
+// ListActionDefinitions returns full definitions filtered by trigger type
+// ("self-service" or "automation"). An empty trigger type returns both.
+func (s *Service) ListActionDefinitions(ctx context.Context, triggerType string) ([]ActionDefinition, error) {
 	params := url.Values{}
-	params.Set("trigger_type", "automation")
+	if triggerType != "" {
+		params.Set("trigger_type", triggerType)
+	}
+	params.Set("version", "v2")
+	var raw json.RawMessage
+	if err := s.doer.Do(ctx, "GET", "/v1/actions?"+params.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+	return decodeActionDefinitionList[ActionDefinition](raw)
+}
+
+// ListActionDefinitionsRaw is ListActionDefinitions returning each
+// definition as sent by the API, including fields ActionDefinition does not
+// model.
+func (s *Service) ListActionDefinitionsRaw(ctx context.Context, triggerType string) ([]json.RawMessage, error) {
+	params := url.Values{}
+	if triggerType != "" {
+		params.Set("trigger_type", triggerType)
+	}
 	params.Set("version", "v2")
 	var raw json.RawMessage
 	if err := s.doer.Do(ctx, "GET", "/v1/actions?"+params.Encode(), nil, &raw); err != nil {
 		return nil, err
 	}
-	return decodeActionDefinitionList(raw)
+	return decodeActionDefinitionList[json.RawMessage](raw)
 }
 
 // GetActionDefinition fetches the full action/automation payload.
@@ -231,16 +255,18 @@ func (s *Service) DeleteAction(ctx context.Context, identifier string) error {
 	return s.doer.Do(ctx, "DELETE", path, nil, nil)
 }
 
-func decodeActionDefinitionList(raw json.RawMessage) ([]ActionDefinition, error) {
+// decodeActionDefinitionList accepts a wrapped or plain list. T is
+// ActionDefinition, or json.RawMessage to keep fields it does not model.
+func decodeActionDefinitionList[T any](raw json.RawMessage) ([]T, error) {
 	var wrap struct {
-		Actions *[]ActionDefinition `json:"actions"`
+		Actions *[]T `json:"actions"`
 	}
 	if err := json.Unmarshal(raw, &wrap); err == nil {
 		if wrap.Actions != nil {
 			return *wrap.Actions, nil
 		}
 	}
-	var plain []ActionDefinition
+	var plain []T
 	if err := json.Unmarshal(raw, &plain); err == nil {
 		return plain, nil
 	}
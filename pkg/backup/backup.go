@@ -0,0 +1,354 @@
+// Package backup snapshots a Port organization into a directory (optionally
+// packed as a tar.gz archive) and restores it in dependency order.
+//
+// A snapshot contains manifest.json plus one JSON file per resource:
+//
+//	blueprints/<id>.json
+//	entities/<blueprint>.jsonl   one entity per line
+//	scorecards/<blueprint>.json
+//	actions/<id>.json            self-service actions and automations
+//	pages/<id>.json
+//	webhooks/<id>.json
+//	integrations/<id>.json
+//	teams.json
+//	users.json                   user email to role and team mapping
+//
+// Snapshots contain webhook secrets and should be stored accordingly; files
+// are written with 0600 permissions.
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/automations"
+	"github.com/port-experimental/port-go-sdk/pkg/blueprints"
+	"github.com/port-experimental/port-go-sdk/pkg/datasources"
+	"github.com/port-experimental/port-go-sdk/pkg/entities"
+	"github.com/port-experimental/port-go-sdk/pkg/users"
+	"github.com/port-experimental/port-go-sdk/pkg/version"
+)
+
+// FormatVersion is the snapshot layout version written to the manifest.
+// Restore rejects snapshots with a newer version.
+const FormatVersion = 1
+
+// Doer matches client.Client.
+type Doer interface {
+	Do(ctx context.Context, method, path string, body any, out any) error
+}
+
+// Service creates and restores snapshots.
+type Service struct {
+	doer        Doer
+	blueprints  *blueprints.Service
+	entities    *entities.Service
+	actions     *automations.Service
+	datasources *datasources.Service
+	users       *users.Service
+}
+
+// New returns a backup service.
+func New(doer Doer) *Service {
+	return &Service{
+		doer:        doer,
+		blueprints:  blueprints.New(doer),
+		entities:    entities.New(doer),
+		actions:     automations.New(doer),
+		datasources: datasources.New(doer),
+		users:       users.New(doer),
+	}
+}
+
+// Manifest describes a snapshot.
+type Manifest struct {
+	FormatVersion int       `json:"formatVersion"`
+	SDKVersion    string    `json:"sdkVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	// Blueprints lists the blueprints in creation order.
+	Blueprints []string `json:"blueprints"`
+	// Counts reports the number of resources per kind.
+	Counts map[string]int `json:"counts"`
+	// Entities reports the number of entities per blueprint.
+	Entities map[string]int `json:"entities"`
+}
+
+// Options configure Backup.
+type Options struct {
+	// Blueprints limits the snapshot to these blueprints and their entities
+	// and scorecards. Organization-wide resources are skipped when set.
+	Blueprints []string
+	// SkipEntities omits entities from the snapshot.
+	SkipEntities bool
+	// PageSize is the entity search page size. Defaults to 500.
+	PageSize int
+}
+
+// UserRoles is the role and team mapping of a user.
+type UserRoles struct {
+	Email string   `json:"email"`
+	Roles []string `json:"roles,omitempty"`
+	Teams []string `json:"teams,omitempty"`
+}
+
+// Backup writes a snapshot of the organization into dir, which must be empty
+// or not exist. Entities are streamed page by page, so memory use does not
+// grow with the catalog size. The manifest is written last; a directory
+// without manifest.json is an incomplete snapshot.
+func (s *Service) Backup(ctx context.Context, dir string, opts *Options) (*Manifest, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("backup: %s is not empty", dir)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+	m := &Manifest{
+		FormatVersion: FormatVersion,
+		SDKVersion:    version.Version,
+		CreatedAt:     time.Now().UTC(),
+		Counts:        map[string]int{},
+		Entities:      map[string]int{},
+	}
+	w := &writer{dir: dir}
+
+	// Definitions are stored as sent by the API: the typed structs only
+	// model a subset of their fields.
+	raws, err := s.blueprints.ListRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("backup: list blueprints: %w", err)
+	}
+	raws = filterBlueprints(raws, opts.Blueprints)
+	bps := make(map[string]json.RawMessage, len(raws))
+	for _, raw := range raws {
+		bps[rawIdentifier(raw)] = raw
+	}
+	plan, err := blueprints.NewRawPlan(raws)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+	m.Blueprints = plan.Order
+	for _, id := range plan.Order {
+		if err := w.json(filepath.Join("blueprints", fileName(id)), bps[id]); err != nil {
+			return nil, err
+		}
+		m.Counts["blueprints"]++
+
+		var cards struct {
+			Scorecards []json.RawMessage `json:"scorecards"`
+		}
+		if err := s.doer.Do(ctx, "GET", fmt.Sprintf("/v1/blueprints/%s/scorecards", url.PathEscape(id)), nil, &cards); err != nil {
+			return nil, fmt.Errorf("backup: scorecards of %s: %w", id, err)
+		}
+		if len(cards.Scorecards) > 0 {
+			if err := w.json(filepath.Join("scorecards", fileName(id)), cards.Scorecards); err != nil {
+				return nil, err
+			}
+			m.Counts["scorecards"] += len(cards.Scorecards)
+		}
+
+		if !opts.SkipEntities {
+			n, err := s.backupEntities(ctx, w, id, opts.PageSize)
+			if err != nil {
+				return nil, err
+			}
+			m.Entities[id] = n
+			m.Counts["entities"] += n
+		}
+	}
+	if len(opts.Blueprints) == 0 {
+		if err := s.backupOrgResources(ctx, w, m); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.json("manifest.json", m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *Service) backupEntities(ctx context.Context, w *writer, blueprint string, pageSize int) (int, error) {
+	if pageSize <= 0 {
+		pageSize = 500
+	}
+	f, err := w.create(filepath.Join("entities", url.PathEscape(blueprint)+".jsonl"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	buf := bufio.NewWriter(f)
+	enc := json.NewEncoder(buf)
+	count := 0
+	opts := entities.SearchOptions{Query: entities.And().Map(), Limit: pageSize}
+	for {
+		resp, err := s.entities.SearchBlueprint(ctx, blueprint, opts)
+		if err != nil {
+			return count, fmt.Errorf("backup: entities of %s: %w", blueprint, err)
+		}
+		for _, ent := range resp.Entities {
+			if err := enc.Encode(ent); err != nil {
+				return count, fmt.Errorf("backup: %w", err)
+			}
+			count++
+		}
+		if !resp.HasMore() {
+			break
+		}
+		opts.From = resp.Next
+	}
+	if err := buf.Flush(); err != nil {
+		return count, fmt.Errorf("backup: %w", err)
+	}
+	return count, f.Close()
+}
+
+func (s *Service) backupOrgResources(ctx context.Context, w *writer, m *Manifest) error {
+	actions, err := s.actions.ListActionDefinitionsRaw(ctx, "")
+	if err != nil {
+		return fmt.Errorf("backup: list actions: %w", err)
+	}
+	for _, a := range actions {
+		if err := w.json(filepath.Join("actions", fileName(rawIdentifier(a))), a); err != nil {
+			return err
+		}
+	}
+	m.Counts["actions"] = len(actions)
+
+	var pages struct {
+		Pages []map[string]any `json:"pages"`
+	}
+	if err := s.doer.Do(ctx, "GET", "/v1/pages", nil, &pages); err != nil {
+		return fmt.Errorf("backup: list pages: %w", err)
+	}
+	for _, p := range pages.Pages {
+		id, _ := p["identifier"].(string)
+		if id == "" {
+			continue
+		}
+		if err := w.json(filepath.Join("pages", fileName(id)), p); err != nil {
+			return err
+		}
+		m.Counts["pages"]++
+	}
+
+	hooks, err := s.datasources.ListWebhooksRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("backup: list webhooks: %w", err)
+	}
+	for _, h := range hooks {
+		if err := w.json(filepath.Join("webhooks", fileName(rawIdentifier(h))), h); err != nil {
+			return err
+		}
+	}
+	m.Counts["webhooks"] = len(hooks)
+
+	integrations, err := s.datasources.ListIntegrations(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("backup: list integrations: %w", err)
+	}
+	for _, integ := range integrations {
+		if err := w.json(filepath.Join("integrations", fileName(integ.Identifier)), integ); err != nil {
+			return err
+		}
+	}
+	m.Counts["integrations"] = len(integrations)
+
+	teams, err := s.users.ListTeams(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("backup: list teams: %w", err)
+	}
+	if err := w.json("teams.json", teams); err != nil {
+		return err
+	}
+	m.Counts["teams"] = len(teams)
+
+	list, err := s.users.ListUsers(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("backup: list users: %w", err)
+	}
+	roles := make([]UserRoles, 0, len(list))
+	for _, u := range list {
+		ur := UserRoles{Email: u.Email, Teams: u.Teams}
+		for _, r := range u.Roles {
+			ur.Roles = append(ur.Roles, r.Name)
+		}
+		roles = append(roles, ur)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Email < roles[j].Email })
+	if err := w.json("users.json", roles); err != nil {
+		return err
+	}
+	m.Counts["users"] = len(roles)
+	return nil
+}
+
+func filterBlueprints(bps []json.RawMessage, only []string) []json.RawMessage {
+	if len(only) == 0 {
+		return bps
+	}
+	keep := map[string]bool{}
+	for _, id := range only {
+		keep[id] = true
+	}
+	var out []json.RawMessage
+	for _, bp := range bps {
+		if keep[rawIdentifier(bp)] {
+			out = append(out, bp)
+		}
+	}
+	return out
+}
+
+// rawIdentifier returns the identifier of a raw definition.
+func rawIdentifier(raw json.RawMessage) string {
+	var v struct {
+		Identifier string `json:"identifier"`
+	}
+	_ = json.Unmarshal(raw, &v)
+	return v.Identifier
+}
+
+// fileName escapes an identifier for use as a file name.
+func fileName(id string) string {
+	return url.PathEscape(id) + ".json"
+}
+
+// writer creates snapshot files below dir.
+type writer struct {
+	dir string
+}
+
+func (w *writer) create(name string) (*os.File, error) {
+	full := filepath.Join(w.dir, name)
+	if err := os.MkdirAll(filepath.Dir(full), 0o700); err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+	f, err := os.OpenFile(full, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+	return f, nil
+}
+
+func (w *writer) json(name string, v any) error {
+	f, err := w.create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		f.Close()
+		return fmt.Errorf("backup: %s: %w", name, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,193 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/port-experimental/port-go-sdk/pkg/porter"
+)
+
+// fakeOrg serves canned responses keyed by "METHOD path" and records every
+// write. Requests listed in errs fail with the given status code.
+type fakeOrg struct {
+	responses map[string]any
+	errs      map[string]int
+	writes    []string
+	bodies    map[string]any
+}
+
+func (f *fakeOrg) Do(ctx context.Context, method, path string, body any, out any) error {
+	route, _, _ := strings.Cut(path, "?")
+	key := method + " " + route
+	if code, ok := f.errs[key]; ok {
+		return &porter.Error{StatusCode: code}
+	}
+	if method != "GET" {
+		f.writes = append(f.writes, key)
+		if f.bodies == nil {
+			f.bodies = map[string]any{}
+		}
+		data, _ := json.Marshal(body)
+		var generic any
+		_ = json.Unmarshal(data, &generic)
+		f.bodies[key] = generic
+	}
+	if resp, ok := f.responses[key]; ok && out != nil {
+		data, _ := json.Marshal(resp)
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+func sourceOrg() *fakeOrg {
+	return &fakeOrg{responses: map[string]any{
+		"GET /v1/blueprints": map[string]any{"blueprints": []any{
+			map[string]any{"identifier": "service", "title": "Service", "relations": map[string]any{"team": map[string]any{"title": "Team", "target": "team"}}},
+			map[string]any{"identifier": "team", "title": "Team", "createdAt": "2024-01-01",
+				"relations": map[string]any{"parent": map[string]any{"title": "Parent", "target": "team"}},
+				"ownership": map[string]any{"type": "Direct"}, "aggregationProperties": map[string]any{"count": map[string]any{"type": "number"}}},
+		}},
+		"GET /v1/blueprints/service/scorecards": map[string]any{"scorecards": []any{
+			map[string]any{"identifier": "prod", "title": "Production", "blueprint": "service", "id": "sc_1", "rules": []any{}},
+		}},
+		"POST /v1/blueprints/service/entities/search": map[string]any{"entities": []any{
+			map[string]any{"identifier": "api", "blueprint": "service", "relations": map[string]any{"team": []any{"core"}}},
+			map[string]any{"identifier": "web", "blueprint": "service"},
+		}},
+		"POST /v1/blueprints/team/entities/search": map[string]any{"entities": []any{
+			map[string]any{"identifier": "core", "blueprint": "team"},
+		}},
+		"GET /v1/actions": map[string]any{"actions": []any{
+			map[string]any{"identifier": "deploy", "title": "Deploy", "trigger": map[string]any{"type": "self-service"}, "approvalNotification": map[string]any{"type": "email"}},
+		}},
+		"GET /v1/pages":    map[string]any{"pages": []any{map[string]any{"identifier": "home", "type": "home", "createdAt": "2024-01-01"}}},
+		"GET /v1/webhooks": map[string]any{"webhooks": []any{map[string]any{"identifier": "gh", "title": "GitHub", "enabled": true, "url": "https://ingest/x", "webhookKey": "x", "customField": 1}}},
+		"GET /v1/integration": map[string]any{"integrations": []any{
+			map[string]any{"identifier": "k8s", "config": map[string]any{"resources": []any{}}},
+		}},
+		"GET /v1/teams": map[string]any{"teams": []any{map[string]any{"name": "core", "description": "Core"}}},
+		"GET /v1/users": map[string]any{"users": []any{
+			map[string]any{"email": "b@example.com", "roles": []any{map[string]any{"name": "Member"}}},
+			map[string]any{"email": "a@example.com", "roles": []any{map[string]any{"name": "Admin"}}, "teams": []any{"core"}},
+		}},
+	}}
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "snap")
+	m, err := New(sourceOrg()).Backup(ctx, dir, nil)
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if want := []string{"team", "service"}; !reflect.DeepEqual(m.Blueprints, want) {
+		t.Fatalf("blueprint order %v", m.Blueprints)
+	}
+	if m.Counts["entities"] != 3 || m.Entities["service"] != 2 || m.Counts["scorecards"] != 1 || m.Counts["users"] != 2 {
+		t.Fatalf("counts %+v %+v", m.Counts, m.Entities)
+	}
+	info, err := os.Stat(filepath.Join(dir, "webhooks", "gh.json"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("webhook file: %v %v", info, err)
+	}
+	if _, err := New(sourceOrg()).Backup(ctx, dir, nil); err == nil {
+		t.Fatal("expected error for non-empty directory")
+	}
+
+	target := &fakeOrg{
+		responses: map[string]any{
+			"GET /v1/integration/k8s": map[string]any{"integration": map[string]any{"identifier": "k8s"}},
+			// Both blueprints already exist and are updated in place.
+			"GET /v1/blueprints": map[string]any{"blueprints": []any{map[string]any{"identifier": "team"}, map[string]any{"identifier": "service"}}},
+			"POST /v1/webhooks":  map[string]any{"webhook": map[string]any{"identifier": "gh"}},
+		},
+		errs: map[string]int{
+			"POST /v1/actions":                409,
+			"PATCH /v1/users/b%40example.com": 404,
+		},
+	}
+	report, err := New(target).Restore(ctx, os.DirFS(dir), nil)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	wantWrites := []string{
+		"POST /v1/teams",
+		"PUT /v1/blueprints/service",
+		"PUT /v1/blueprints/team",
+		"PUT /v1/blueprints/service/scorecards",
+		"POST /v1/blueprints/team/entities/bulk",
+		"POST /v1/blueprints/service/entities/bulk",
+		"POST /v1/blueprints/service/entities/bulk",
+		"PUT /v1/actions/deploy",
+		"POST /v1/pages",
+		"POST /v1/webhooks",
+		"PATCH /v1/integration/k8s/config",
+		"PATCH /v1/users/a%40example.com",
+	}
+	if !reflect.DeepEqual(target.writes, wantWrites) {
+		t.Fatalf("writes\n got %v\nwant %v", target.writes, wantWrites)
+	}
+	cards := target.bodies["PUT /v1/blueprints/service/scorecards"].([]any)
+	if card := cards[0].(map[string]any); card["id"] != nil || card["blueprint"] != nil || card["identifier"] != "prod" {
+		t.Fatalf("scorecard body %v", card)
+	}
+	// Fields the typed structs do not model survive the round trip, while
+	// server-managed fields are not written back.
+	team := target.bodies["PUT /v1/blueprints/team"].(map[string]any)
+	if team["ownership"] == nil || team["aggregationProperties"] == nil || team["createdAt"] != nil {
+		t.Fatalf("blueprint body %v", team)
+	}
+	// Existing blueprints are replaced with their relations in the same
+	// PUT; sending them without would delete the relation's entity data.
+	if rels, _ := team["relations"].(map[string]any); rels["parent"] == nil {
+		t.Fatalf("existing blueprint restored without its self-relation: %v", team)
+	}
+	if action := target.bodies["PUT /v1/actions/deploy"].(map[string]any); action["approvalNotification"] == nil {
+		t.Fatalf("action body %v", action)
+	}
+	if hook := target.bodies["POST /v1/webhooks"].(map[string]any); hook["customField"] != 1.0 || hook["url"] != nil || hook["webhookKey"] != nil {
+		t.Fatalf("webhook body %v", hook)
+	}
+	if len(report.Skipped) != 1 || !strings.Contains(report.Skipped[0], "b@example.com") {
+		t.Fatalf("skipped %v", report.Skipped)
+	}
+	if report.Counts["entities"] != 3 || report.Counts["actions"] != 1 {
+		t.Fatalf("report counts %+v", report.Counts)
+	}
+
+	filtered := &fakeOrg{}
+	if _, err := New(filtered).Restore(ctx, os.DirFS(dir), &RestoreOptions{Blueprints: []string{"team"}, SkipEntities: true}); err != nil {
+		t.Fatalf("filtered restore: %v", err)
+	}
+	if want := []string{"POST /v1/blueprints"}; !reflect.DeepEqual(filtered.writes, want) {
+		t.Fatalf("filtered writes %v", filtered.writes)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	if _, err := New(sourceOrg()).Backup(context.Background(), src, &Options{SkipEntities: true}); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, src); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	dst := t.TempDir()
+	if err := ExtractArchive(&buf, dst); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	for _, name := range []string{"manifest.json", "blueprints/service.json", "users.json"} {
+		want, _ := os.ReadFile(filepath.Join(src, name))
+		got, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("%s differs after round trip: %v", name, err)
+		}
+	}
+}
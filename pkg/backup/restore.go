@@ -0,0 +1,392 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+
+	"github.com/port-experimental/port-go-sdk/pkg/blueprints"
+	"github.com/port-experimental/port-go-sdk/pkg/datasources"
+	"github.com/port-experimental/port-go-sdk/pkg/entities"
+	"github.com/port-experimental/port-go-sdk/pkg/porter"
+	"github.com/port-experimental/port-go-sdk/pkg/users"
+)
+
+// RestoreOptions configure Restore.
+type RestoreOptions struct {
+	// Blueprints limits the restore to these blueprints and their entities
+	// and scorecards. Organization-wide resources are skipped when set.
+	Blueprints []string
+	// SkipEntities restores definitions only.
+	SkipEntities bool
+}
+
+// RestoreReport summarizes a restore.
+type RestoreReport struct {
+	// Counts reports the number of restored resources per kind.
+	Counts map[string]int
+	// Skipped lists resources that could not be restored into this
+	// organization, such as users that were never invited.
+	Skipped []string
+}
+
+// bulkBatch is the largest batch accepted by entities.Service.BulkUpsert.
+const bulkBatch = 20
+
+// serverFields are read-only attributes stripped from raw resources before
+// they are written back.
+var serverFields = []string{"id", "createdAt", "createdBy", "updatedAt", "updatedBy"}
+
+// webhookServerFields are additional read-only attributes of webhooks.
+var webhookServerFields = []string{"orgId", "url", "webhookKey"}
+
+// Restore recreates a snapshot read from fsys (for example os.DirFS(dir)).
+// Resources are restored in dependency order: teams, blueprints (cyclic
+// relations patched in afterwards), scorecards, entities, then actions,
+// pages, webhooks, integration configs and user roles. Existing resources
+// are updated; existing blueprints are replaced with one PUT of their full
+// definition, so their relations and the entity data in them are kept.
+// Entities are written in two passes, first without relations and then with
+// them, so relations between entities resolve regardless of order.
+func (s *Service) Restore(ctx context.Context, fsys fs.FS, opts *RestoreOptions) (*RestoreReport, error) {
+	if opts == nil {
+		opts = &RestoreOptions{}
+	}
+	var m Manifest
+	if err := readJSON(fsys, "manifest.json", &m); err != nil {
+		return nil, fmt.Errorf("backup: read manifest: %w", err)
+	}
+	if m.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("backup: snapshot format %d is newer than supported format %d", m.FormatVersion, FormatVersion)
+	}
+	report := &RestoreReport{Counts: map[string]int{}}
+	filtered := len(opts.Blueprints) > 0
+	selected := m.Blueprints
+	if filtered {
+		keep := map[string]bool{}
+		for _, id := range opts.Blueprints {
+			keep[id] = true
+		}
+		selected = nil
+		for _, id := range m.Blueprints {
+			if keep[id] {
+				selected = append(selected, id)
+			}
+		}
+	}
+
+	if !filtered {
+		if err := s.restoreTeams(ctx, fsys, report); err != nil {
+			return report, err
+		}
+	}
+	if err := s.restoreBlueprints(ctx, fsys, selected, report); err != nil {
+		return report, err
+	}
+	if !opts.SkipEntities {
+		for _, withRelations := range []bool{false, true} {
+			for _, id := range selected {
+				if err := s.restoreEntities(ctx, fsys, id, withRelations, report); err != nil {
+					return report, err
+				}
+			}
+		}
+	}
+	if filtered {
+		return report, nil
+	}
+	for _, step := range []func(context.Context, fs.FS, *RestoreReport) error{
+		s.restoreActions, s.restorePages, s.restoreWebhooks, s.restoreIntegrations, s.restoreUsers,
+	} {
+		if err := step(ctx, fsys, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (s *Service) restoreTeams(ctx context.Context, fsys fs.FS, report *RestoreReport) error {
+	var teams []users.Team
+	if err := readJSON(fsys, "teams.json", &teams); err != nil {
+		return optional(err)
+	}
+	for _, t := range teams {
+		// Membership is restored from users.json once users are known to exist.
+		_, err := s.users.CreateTeam(ctx, users.TeamCreateRequest{Name: t.Name, Description: t.Description})
+		if porter.IsConflict(err) {
+			desc := t.Description
+			_, err = s.users.PatchTeam(ctx, t.Name, users.TeamPatchRequest{Description: &desc})
+		}
+		if err != nil {
+			return fmt.Errorf("backup: restore team %s: %w", t.Name, err)
+		}
+		report.Counts["teams"]++
+	}
+	return nil
+}
+
+func (s *Service) restoreBlueprints(ctx context.Context, fsys fs.FS, ids []string, report *RestoreReport) error {
+	bps := make([]json.RawMessage, 0, len(ids))
+	for _, id := range ids {
+		bp, err := readDefinition(fsys, path.Join("blueprints", fileName(id)))
+		if err != nil {
+			return fmt.Errorf("backup: read blueprint %s: %w", id, err)
+		}
+		raw, err := json.Marshal(bp)
+		if err != nil {
+			return fmt.Errorf("backup: read blueprint %s: %w", id, err)
+		}
+		bps = append(bps, raw)
+	}
+	plan, err := blueprints.NewRawPlan(bps)
+	if err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	if err := s.blueprints.Apply(ctx, plan); err != nil {
+		return fmt.Errorf("backup: restore blueprints: %w", err)
+	}
+	report.Counts["blueprints"] = len(bps)
+
+	for _, id := range ids {
+		var cards []map[string]any
+		if err := readJSON(fsys, path.Join("scorecards", fileName(id)), &cards); err != nil {
+			if err = optional(err); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, c := range cards {
+			stripServerFields(c)
+			delete(c, "blueprint")
+		}
+		p := fmt.Sprintf("/v1/blueprints/%s/scorecards", url.PathEscape(id))
+		if err := s.doer.Do(ctx, "PUT", p, cards, nil); err != nil {
+			return fmt.Errorf("backup: restore scorecards of %s: %w", id, err)
+		}
+		report.Counts["scorecards"] += len(cards)
+	}
+	return nil
+}
+
+// restoreEntities streams entities/<blueprint>.jsonl in batches. The first
+// pass writes entities without relations; the second rewrites only entities
+// that have relations.
+func (s *Service) restoreEntities(ctx context.Context, fsys fs.FS, blueprint string, withRelations bool, report *RestoreReport) error {
+	f, err := fsys.Open(path.Join("entities", url.PathEscape(blueprint)+".jsonl"))
+	if err != nil {
+		return optional(err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(bufio.NewReader(f))
+	batch := make([]entities.Entity, 0, bulkBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := s.entities.BulkUpsert(ctx, blueprint, batch); err != nil {
+			return fmt.Errorf("backup: restore entities of %s: %w", blueprint, err)
+		}
+		if !withRelations {
+			report.Counts["entities"] += len(batch)
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		var ent entities.Entity
+		if err := dec.Decode(&ent); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("backup: read entities of %s: %w", blueprint, err)
+		}
+		if withRelations {
+			if len(ent.Relations) == 0 {
+				continue
+			}
+		} else {
+			ent.Relations = nil
+		}
+		batch = append(batch, ent)
+		if len(batch) == bulkBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+func (s *Service) restoreActions(ctx context.Context, fsys fs.FS, report *RestoreReport) error {
+	return eachFile(fsys, "actions", func(name string) error {
+		a, err := readDefinition(fsys, name)
+		if err != nil {
+			return err
+		}
+		id, _ := a["identifier"].(string)
+		err = s.doer.Do(ctx, "POST", "/v1/actions", a, nil)
+		if porter.IsConflict(err) {
+			err = s.doer.Do(ctx, "PUT", "/v1/actions/"+url.PathEscape(id), a, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("backup: restore action %s: %w", id, err)
+		}
+		report.Counts["actions"]++
+		return nil
+	})
+}
+
+func (s *Service) restorePages(ctx context.Context, fsys fs.FS, report *RestoreReport) error {
+	return eachFile(fsys, "pages", func(name string) error {
+		var page map[string]any
+		if err := readJSON(fsys, name, &page); err != nil {
+			return err
+		}
+		stripServerFields(page)
+		id, _ := page["identifier"].(string)
+		err := s.doer.Do(ctx, "POST", "/v1/pages", page, nil)
+		if porter.IsConflict(err) {
+			err = s.doer.Do(ctx, "PATCH", "/v1/pages/"+url.PathEscape(id), page, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("backup: restore page %s: %w", id, err)
+		}
+		report.Counts["pages"]++
+		return nil
+	})
+}
+
+func (s *Service) restoreWebhooks(ctx context.Context, fsys fs.FS, report *RestoreReport) error {
+	return eachFile(fsys, "webhooks", func(name string) error {
+		h, err := readDefinition(fsys, name, webhookServerFields...)
+		if err != nil {
+			return err
+		}
+		id, _ := h["identifier"].(string)
+		err = s.doer.Do(ctx, "POST", "/v1/webhooks", h, nil)
+		if porter.IsConflict(err) {
+			err = s.doer.Do(ctx, "PATCH", "/v1/webhooks/"+url.PathEscape(id), h, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("backup: restore webhook %s: %w", id, err)
+		}
+		report.Counts["webhooks"]++
+		return nil
+	})
+}
+
+func (s *Service) restoreIntegrations(ctx context.Context, fsys fs.FS, report *RestoreReport) error {
+	return eachFile(fsys, "integrations", func(name string) error {
+		var integ datasources.Integration
+		if err := readJSON(fsys, name, &integ); err != nil {
+			return err
+		}
+		// Integrations are installed outside the API; only their mapping
+		// config can be restored.
+		if _, err := s.datasources.GetIntegration(ctx, integ.Identifier, nil); porter.IsNotFound(err) {
+			report.Skipped = append(report.Skipped, "integration/"+integ.Identifier+": not installed")
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("backup: restore integration %s: %w", integ.Identifier, err)
+		}
+		if len(integ.Config) == 0 {
+			return nil
+		}
+		if err := s.datasources.UpdateIntegrationConfig(ctx, integ.Identifier, datasources.IntegrationConfigRequest{Config: integ.Config}); err != nil {
+			return fmt.Errorf("backup: restore integration %s: %w", integ.Identifier, err)
+		}
+		report.Counts["integrations"]++
+		return nil
+	})
+}
+
+func (s *Service) restoreUsers(ctx context.Context, fsys fs.FS, report *RestoreReport) error {
+	var list []UserRoles
+	if err := readJSON(fsys, "users.json", &list); err != nil {
+		return optional(err)
+	}
+	for _, u := range list {
+		if len(u.Roles) == 0 && len(u.Teams) == 0 {
+			continue
+		}
+		_, err := s.users.UpdateUser(ctx, u.Email, users.UpdateUserRequest{Roles: u.Roles, Teams: u.Teams})
+		if porter.IsNotFound(err) {
+			report.Skipped = append(report.Skipped, "user/"+u.Email+": not a member of this organization")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("backup: restore user %s: %w", u.Email, err)
+		}
+		report.Counts["users"]++
+	}
+	return nil
+}
+
+func readJSON(fsys fs.FS, name string, v any) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// readDefinition reads a raw definition without its server-managed fields
+// and the extra fields named. Numbers are kept as json.Number so they are
+// written back exactly.
+func readDefinition(fsys fs.FS, name string, extra ...string) (map[string]any, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	stripServerFields(m)
+	for _, k := range extra {
+		delete(m, k)
+	}
+	return m, nil
+}
+
+// eachFile calls fn for every JSON file in dir, in name order. A missing
+// directory is not an error.
+func eachFile(fsys fs.FS, dir string, fn func(name string) error) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return optional(err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".json" {
+			continue
+		}
+		if err := fn(path.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// optional ignores missing files, which snapshots taken with a blueprint
+// filter or without entities legitimately lack.
+func optional(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return fmt.Errorf("backup: %w", err)
+}
+
+func stripServerFields(m map[string]any) {
+	for _, k := range serverFields {
+		delete(m, k)
+	}
+}
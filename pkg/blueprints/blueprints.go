@@ -2,6 +2,7 @@ package blueprints
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -32,6 +33,25 @@ type Blueprint struct {
 	Schema      map[string]interface{} `json:"schema"`
 	Icon        string                 `json:"icon,omitempty"`
 	Relations   map[string]Relation    `json:"relations,omitempty"`
+
+	MirrorProperties      map[string]MirrorProperty      `json:"mirrorProperties,omitempty"`
+	CalculationProperties map[string]CalculationProperty `json:"calculationProperties,omitempty"`
+}
+
+// MirrorProperty exposes a property of a related entity.
+type MirrorProperty struct {
+	Title string `json:"title,omitempty"`
+	Path  string `json:"path"`
+}
+
+// CalculationProperty is a property computed with a JQ expression.
+type CalculationProperty struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Calculation string `json:"calculation"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
 }
 
 // List returns all blueprints.
@@ -46,6 +66,18 @@ func (s *Service) List(ctx context.Context) ([]Blueprint, error) {
 	return resp.Blueprints, nil
 }
 
+// ListRaw returns every blueprint as sent by the API, including fields
+// Blueprint does not model, for callers that write them back unchanged.
+func (s *Service) ListRaw(ctx context.Context) ([]json.RawMessage, error) {
+	var resp struct {
+		Blueprints []json.RawMessage `json:"blueprints"`
+	}
+	if err := s.doer.Do(ctx, "GET", "/v1/blueprints", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Blueprints, nil
+}
+
 // Get fetches a blueprint by identifier.
 // The context controls the request lifetime. Recommended timeout: 30 seconds.
 func (s *Service) Get(ctx context.Context, identifier string) (Blueprint, error) {
@@ -61,14 +93,18 @@ func (s *Service) Get(ctx context.Context, identifier string) (Blueprint, error)
 
 // Upsert creates or updates a blueprint using PUT, falling back to POST when the blueprint doesn't exist yet.
 func (s *Service) Upsert(ctx context.Context, bp Blueprint) error {
-	if bp.Identifier == "" {
+	return s.upsert(ctx, bp.Identifier, bp)
+}
+
+func (s *Service) upsert(ctx context.Context, identifier string, body any) error {
+	if identifier == "" {
 		return fmt.Errorf("blueprint identifier required")
 	}
-	path := fmt.Sprintf("/v1/blueprints/%s", url.PathEscape(bp.Identifier))
-	if err := s.doer.Do(ctx, "PUT", path, bp, nil); err != nil {
+	path := fmt.Sprintf("/v1/blueprints/%s", url.PathEscape(identifier))
+	if err := s.doer.Do(ctx, "PUT", path, body, nil); err != nil {
 		var perr *porter.Error
 		if errors.As(err, &perr) && perr.StatusCode == 404 {
-			return s.doer.Do(ctx, "POST", "/v1/blueprints", bp, nil)
+			return s.doer.Do(ctx, "POST", "/v1/blueprints", body, nil)
 		}
 		return err
 	}
@@ -0,0 +1,465 @@
+package blueprints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Severity classifies the impact of a schema change on existing entities and
+// their consumers.
+type Severity int
+
+// Change severities, ordered from least to most impactful.
+const (
+	// SeveritySafe changes cannot invalidate existing entities or writers.
+	SeveritySafe Severity = iota
+	// SeverityRisky changes may reject writes that used to succeed, such as a
+	// narrowed enum or a newly required property.
+	SeverityRisky
+	// SeverityBreaking changes lose data or break readers, such as a removed
+	// property, a type change or a retargeted relation.
+	SeverityBreaking
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeveritySafe:
+		return "safe"
+	case SeverityRisky:
+		return "risky"
+	case SeverityBreaking:
+		return "breaking"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// ChangeKind describes what happened to a schema element.
+type ChangeKind string
+
+// Change kinds reported by Diff.
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+	ChangeRenamed ChangeKind = "renamed"
+)
+
+// Schema sections compared by Diff.
+const (
+	SectionBlueprint   = "blueprint"
+	SectionProperty    = "property"
+	SectionRelation    = "relation"
+	SectionMirror      = "mirrorProperty"
+	SectionCalculation = "calculationProperty"
+)
+
+// Change is a single difference between two blueprint versions.
+type Change struct {
+	Kind    ChangeKind
+	Section string
+	// Identifier is the property, relation or blueprint field concerned.
+	// For renames it is the new identifier and Old holds the previous one.
+	Identifier string
+	Old, New   any
+	Severity   Severity
+	Reason     string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("[%s] %s %s %s: %s", c.Severity, c.Section, c.Identifier, c.Kind, c.Reason)
+}
+
+// RenameSuggestion is a removed/added pair with identical definitions that
+// is most likely a rename. Applying it keeps existing entity data, which
+// deleting and recreating the element would lose.
+type RenameSuggestion struct {
+	Section   string
+	Blueprint string
+	From, To  string
+}
+
+// String renders the suggested SDK call.
+func (r RenameSuggestion) String() string {
+	method := map[string]string{
+		SectionProperty: "RenameProperty",
+		SectionRelation: "RenameRelation",
+		SectionMirror:   "RenameMirrorProperty",
+	}[r.Section]
+	return fmt.Sprintf("%s(ctx, %q, %q, %q)", method, r.Blueprint, r.From, r.To)
+}
+
+// Apply performs the rename.
+func (r RenameSuggestion) Apply(ctx context.Context, s *Service) error {
+	switch r.Section {
+	case SectionProperty:
+		return s.RenameProperty(ctx, r.Blueprint, r.From, r.To)
+	case SectionRelation:
+		return s.RenameRelation(ctx, r.Blueprint, r.From, r.To)
+	case SectionMirror:
+		return s.RenameMirrorProperty(ctx, r.Blueprint, r.From, r.To)
+	default:
+		return fmt.Errorf("cannot rename %s", r.Section)
+	}
+}
+
+// SchemaDiff is the result of Diff.
+type SchemaDiff struct {
+	Blueprint string
+	Changes   []Change
+	Renames   []RenameSuggestion
+}
+
+// Empty reports whether the two versions are equivalent.
+func (d SchemaDiff) Empty() bool { return len(d.Changes) == 0 }
+
+// Severity returns the highest severity among the changes.
+func (d SchemaDiff) Severity() Severity {
+	highest := SeveritySafe
+	for _, c := range d.Changes {
+		if c.Severity > highest {
+			highest = c.Severity
+		}
+	}
+	return highest
+}
+
+// AtLeast returns the changes whose severity is at least threshold.
+func (d SchemaDiff) AtLeast(threshold Severity) []Change {
+	var out []Change
+	for _, c := range d.Changes {
+		if c.Severity >= threshold {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// String renders one change per line followed by the rename suggestions,
+// suitable for CI logs.
+func (d SchemaDiff) String() string {
+	var b strings.Builder
+	for _, c := range d.Changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	for _, r := range d.Renames {
+		fmt.Fprintf(&b, "suggestion: %s\n", r)
+	}
+	return b.String()
+}
+
+// Diff compares two versions of a blueprint and classifies each difference.
+// Schema properties, relations, mirror and calculation properties are
+// compared; a removed and an added element with identical definitions are
+// reported as a single rename together with a RenameSuggestion.
+func Diff(before, after Blueprint) SchemaDiff {
+	d := SchemaDiff{Blueprint: after.Identifier}
+	if d.Blueprint == "" {
+		d.Blueprint = before.Identifier
+	}
+	for _, f := range []struct {
+		name          string
+		before, after string
+	}{{"title", before.Title, after.Title}, {"description", before.Description, after.Description}, {"icon", before.Icon, after.Icon}} {
+		if f.before != f.after {
+			d.Changes = append(d.Changes, Change{Kind: ChangeChanged, Section: SectionBlueprint, Identifier: f.name, Old: f.before, New: f.after, Severity: SeveritySafe, Reason: f.name + " changed"})
+		}
+	}
+
+	oldReq, newReq := requiredSet(before.Schema), requiredSet(after.Schema)
+	d.diffSection(SectionProperty, schemaProperties(before.Schema), schemaProperties(after.Schema),
+		func(id string, def any) (Severity, string) {
+			if newReq[id] {
+				return SeverityRisky, "required property added"
+			}
+			return SeveritySafe, "property added"
+		},
+		func(id string, o, n any) (Severity, string) {
+			return compareProperty(asMap(o), asMap(n), oldReq[id], newReq[id])
+		})
+	// Required flags can change without the property definition changing.
+	for id := range newReq {
+		if !oldReq[id] && d.find(SectionProperty, id) < 0 && hasKey(schemaProperties(before.Schema), id) {
+			d.Changes = append(d.Changes, Change{Kind: ChangeChanged, Section: SectionProperty, Identifier: id, Old: false, New: true, Severity: SeverityRisky, Reason: "property became required"})
+		}
+	}
+	for id := range oldReq {
+		if !newReq[id] && d.find(SectionProperty, id) < 0 && hasKey(schemaProperties(after.Schema), id) {
+			d.Changes = append(d.Changes, Change{Kind: ChangeChanged, Section: SectionProperty, Identifier: id, Old: true, New: false, Severity: SeveritySafe, Reason: "property no longer required"})
+		}
+	}
+
+	d.diffSection(SectionRelation, normalizeMap(before.Relations), normalizeMap(after.Relations),
+		func(id string, def any) (Severity, string) {
+			if asMap(def)["required"] == true {
+				return SeverityRisky, "required relation added"
+			}
+			return SeveritySafe, "relation added"
+		},
+		func(id string, o, n any) (Severity, string) {
+			return compareRelation(asMap(o), asMap(n))
+		})
+	d.diffSection(SectionMirror, normalizeMap(before.MirrorProperties), normalizeMap(after.MirrorProperties),
+		func(string, any) (Severity, string) { return SeveritySafe, "mirror property added" },
+		func(id string, o, n any) (Severity, string) {
+			if asMap(o)["path"] != asMap(n)["path"] {
+				return SeverityRisky, "mirror path changed"
+			}
+			return SeveritySafe, "mirror property changed"
+		})
+	d.diffSection(SectionCalculation, normalizeMap(before.CalculationProperties), normalizeMap(after.CalculationProperties),
+		func(string, any) (Severity, string) { return SeveritySafe, "calculation property added" },
+		func(id string, o, n any) (Severity, string) {
+			om, nm := asMap(o), asMap(n)
+			switch {
+			case om["type"] != nm["type"] || om["format"] != nm["format"]:
+				return SeverityBreaking, fmt.Sprintf("type changed from %s to %s", typeName(om), typeName(nm))
+			case om["calculation"] != nm["calculation"]:
+				return SeverityRisky, "calculation changed"
+			}
+			return SeveritySafe, "calculation property changed"
+		})
+
+	sectionRank := map[string]int{SectionBlueprint: 0, SectionProperty: 1, SectionRelation: 2, SectionMirror: 3, SectionCalculation: 4}
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		a, b := d.Changes[i], d.Changes[j]
+		if a.Section != b.Section {
+			return sectionRank[a.Section] < sectionRank[b.Section]
+		}
+		return a.Identifier < b.Identifier
+	})
+	return d
+}
+
+// diffSection compares one map of definitions. Removals are always breaking
+// unless they pair up with an identical addition, which is reported as a rename.
+func (d *SchemaDiff) diffSection(section string, before, after map[string]any,
+	added func(id string, def any) (Severity, string),
+	changed func(id string, o, n any) (Severity, string)) {
+	var removedIDs, addedIDs []string
+	for _, id := range sortedKeys(before) {
+		n, ok := after[id]
+		if !ok {
+			removedIDs = append(removedIDs, id)
+			continue
+		}
+		if reflect.DeepEqual(before[id], n) {
+			continue
+		}
+		sev, reason := changed(id, before[id], n)
+		d.Changes = append(d.Changes, Change{Kind: ChangeChanged, Section: section, Identifier: id, Old: before[id], New: n, Severity: sev, Reason: reason})
+	}
+	for _, id := range sortedKeys(after) {
+		if _, ok := before[id]; !ok {
+			addedIDs = append(addedIDs, id)
+		}
+	}
+
+	// A rename is only suggested when exactly one added element matches.
+	renamedTo := map[string]string{}
+	if section != SectionCalculation {
+		for _, from := range removedIDs {
+			var match []string
+			for _, to := range addedIDs {
+				if sameDefinition(before[from], after[to]) {
+					match = append(match, to)
+				}
+			}
+			if len(match) == 1 && !containsValue(renamedTo, match[0]) {
+				renamedTo[from] = match[0]
+			}
+		}
+	}
+	for _, id := range removedIDs {
+		if to, ok := renamedTo[id]; ok {
+			d.Changes = append(d.Changes, Change{Kind: ChangeRenamed, Section: section, Identifier: to, Old: id, New: to, Severity: SeverityRisky, Reason: fmt.Sprintf("renamed from %s; references to the old identifier break", id)})
+			d.Renames = append(d.Renames, RenameSuggestion{Section: section, Blueprint: d.Blueprint, From: id, To: to})
+			continue
+		}
+		d.Changes = append(d.Changes, Change{Kind: ChangeRemoved, Section: section, Identifier: id, Old: before[id], Severity: SeverityBreaking, Reason: section + " removed"})
+	}
+	for _, id := range addedIDs {
+		if containsValue(renamedTo, id) {
+			continue
+		}
+		sev, reason := added(id, after[id])
+		d.Changes = append(d.Changes, Change{Kind: ChangeAdded, Section: section, Identifier: id, New: after[id], Severity: sev, Reason: reason})
+	}
+}
+
+func (d *SchemaDiff) find(section, id string) int {
+	for i, c := range d.Changes {
+		if c.Section == section && c.Identifier == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func compareProperty(o, n map[string]any, wasRequired, isRequired bool) (Severity, string) {
+	if o["type"] != n["type"] || o["format"] != n["format"] || !reflect.DeepEqual(itemsType(o), itemsType(n)) {
+		return SeverityBreaking, fmt.Sprintf("type changed from %s to %s", typeName(o), typeName(n))
+	}
+	if lost := enumRemoved(o["enum"], n["enum"]); len(lost) > 0 {
+		return SeverityRisky, fmt.Sprintf("enum narrowed, removed %v", lost)
+	}
+	if o["enum"] == nil && n["enum"] != nil {
+		return SeverityRisky, "enum constraint added"
+	}
+	if !wasRequired && isRequired {
+		return SeverityRisky, "property became required"
+	}
+	for _, k := range []string{"pattern", "minLength", "maxLength", "minimum", "maximum"} {
+		if !reflect.DeepEqual(o[k], n[k]) && n[k] != nil {
+			return SeverityRisky, k + " constraint changed"
+		}
+	}
+	return SeveritySafe, "property changed"
+}
+
+func compareRelation(o, n map[string]any) (Severity, string) {
+	switch {
+	case o["target"] != n["target"]:
+		return SeverityBreaking, fmt.Sprintf("target changed from %v to %v", o["target"], n["target"])
+	case o["many"] != n["many"]:
+		return SeverityBreaking, fmt.Sprintf("many changed from %v to %v", o["many"], n["many"])
+	case o["required"] != true && n["required"] == true:
+		return SeverityRisky, "relation became required"
+	}
+	return SeveritySafe, "relation changed"
+}
+
+// sameDefinition compares two definitions ignoring presentation fields.
+func sameDefinition(a, b any) bool {
+	strip := func(v any) any {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		out := make(map[string]any, len(m))
+		for k, val := range m {
+			switch k {
+			case "title", "description", "icon":
+				continue
+			}
+			out[k] = val
+		}
+		return out
+	}
+	return reflect.DeepEqual(strip(a), strip(b))
+}
+
+func enumRemoved(before, after any) []any {
+	oldVals, _ := before.([]any)
+	newVals, ok := after.([]any)
+	if len(oldVals) == 0 || !ok {
+		return nil
+	}
+	var lost []any
+	for _, v := range oldVals {
+		found := false
+		for _, w := range newVals {
+			if reflect.DeepEqual(v, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			lost = append(lost, v)
+		}
+	}
+	return lost
+}
+
+func itemsType(def map[string]any) any {
+	items, _ := def["items"].(map[string]any)
+	if items == nil {
+		return nil
+	}
+	return [2]any{items["type"], items["format"]}
+}
+
+func typeName(def map[string]any) string {
+	name := fmt.Sprint(def["type"])
+	if f, ok := def["format"]; ok {
+		name += "(" + fmt.Sprint(f) + ")"
+	}
+	if it, ok := itemsType(def).([2]any); ok {
+		name += fmt.Sprintf("[%v]", it[0])
+	}
+	return name
+}
+
+func schemaProperties(schema map[string]interface{}) map[string]any {
+	props, _ := normalize(schema["properties"]).(map[string]any)
+	return props
+}
+
+func requiredSet(schema map[string]interface{}) map[string]bool {
+	set := map[string]bool{}
+	if list, ok := normalize(schema["required"]).([]any); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				set[s] = true
+			}
+		}
+	}
+	return set
+}
+
+// normalizeMap converts a typed map into generic JSON values so definitions
+// can be compared field by field.
+func normalizeMap[V any](m map[string]V) map[string]any {
+	out, _ := normalize(m).(map[string]any)
+	return out
+}
+
+// normalize round-trips v through JSON so []string and []any, or int and
+// float64, compare equal.
+func normalize(v any) any {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func hasKey(m map[string]any, k string) bool {
+	_, ok := m[k]
+	return ok
+}
+
+func containsValue(m map[string]string, v string) bool {
+	for _, val := range m {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package blueprints
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffClassifiesChanges(t *testing.T) {
+	before := Blueprint{
+		Identifier: "service",
+		Title:      "Service",
+		Schema: map[string]any{
+			"properties": map[string]any{
+				"tier":     map[string]any{"type": "string", "enum": []string{"gold", "silver", "bronze"}},
+				"replicas": map[string]any{"type": "number"},
+				"url":      map[string]any{"type": "string", "format": "url"},
+				"legacy":   map[string]any{"type": "boolean"},
+				"owner":    map[string]any{"type": "string"},
+			},
+			"required": []string{},
+		},
+		Relations: map[string]Relation{
+			"team":   {Title: "Team", Target: "team"},
+			"domain": {Title: "Domain", Target: "domain"},
+		},
+		MirrorProperties: map[string]MirrorProperty{"teamName": {Path: "team.$title"}},
+	}
+	after := Blueprint{
+		Identifier: "service",
+		Title:      "Services",
+		Schema: map[string]any{
+			"properties": map[string]any{
+				"tier":     map[string]any{"type": "string", "enum": []any{"gold", "silver"}},
+				"replicas": map[string]any{"type": "string"},
+				"link":     map[string]any{"type": "string", "format": "url", "title": "Link"},
+				"owner":    map[string]any{"type": "string"},
+				"region":   map[string]any{"type": "string"},
+			},
+			"required": []string{"owner", "region"},
+		},
+		Relations: map[string]Relation{
+			"owningTeam": {Title: "Team", Target: "team"},
+			"domain":     {Title: "Domain", Target: "system"},
+		},
+		MirrorProperties: map[string]MirrorProperty{"teamName": {Path: "owningTeam.$title"}},
+		CalculationProperties: map[string]CalculationProperty{
+			"slug": {Calculation: ".identifier", Type: "string"},
+		},
+	}
+	d := Diff(before, after)
+	got := map[string]Change{}
+	for _, c := range d.Changes {
+		got[c.Section+"/"+c.Identifier] = c
+	}
+	want := map[string]struct {
+		kind ChangeKind
+		sev  Severity
+	}{
+		"blueprint/title":          {ChangeChanged, SeveritySafe},
+		"property/tier":            {ChangeChanged, SeverityRisky},
+		"property/replicas":        {ChangeChanged, SeverityBreaking},
+		"property/legacy":          {ChangeRemoved, SeverityBreaking},
+		"property/link":            {ChangeRenamed, SeverityRisky},
+		"property/owner":           {ChangeChanged, SeverityRisky},
+		"property/region":          {ChangeAdded, SeverityRisky},
+		"relation/owningTeam":      {ChangeRenamed, SeverityRisky},
+		"relation/domain":          {ChangeChanged, SeverityBreaking},
+		"mirrorProperty/teamName":  {ChangeChanged, SeverityRisky},
+		"calculationProperty/slug": {ChangeAdded, SeveritySafe},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d changes, want %d:\n%s", len(got), len(want), d)
+	}
+	for key, w := range want {
+		c, ok := got[key]
+		if !ok || c.Kind != w.kind || c.Severity != w.sev {
+			t.Errorf("%s: got %+v, want %s/%s", key, c, w.kind, w.sev)
+		}
+	}
+	if d.Severity() != SeverityBreaking || len(d.AtLeast(SeverityBreaking)) != 3 {
+		t.Fatalf("unexpected severity summary:\n%s", d)
+	}
+	if len(d.Renames) != 2 {
+		t.Fatalf("expected 2 rename suggestions, got %v", d.Renames)
+	}
+	if s := d.String(); !strings.Contains(s, `RenameProperty(ctx, "service", "url", "link")`) ||
+		!strings.Contains(s, `RenameRelation(ctx, "service", "team", "owningTeam")`) {
+		t.Fatalf("missing rename suggestions:\n%s", s)
+	}
+	if !Diff(before, before).Empty() {
+		t.Fatalf("identical blueprints should not differ")
+	}
+}
@@ -0,0 +1,412 @@
+package blueprints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// StepAction identifies the operation performed by a plan step.
+type StepAction string
+
+// Plan step actions.
+const (
+	// StepCreate creates a blueprint without its deferred relations.
+	StepCreate StepAction = "create"
+	// StepUpdate replaces an existing blueprint with its full definition.
+	StepUpdate StepAction = "update"
+	// StepAddRelations patches deferred relations into an existing blueprint.
+	StepAddRelations StepAction = "add-relations"
+	// StepDeleteEntities deletes every entity of a blueprint.
+	StepDeleteEntities StepAction = "delete-entities"
+	// StepDropRelations removes deferred relations before a cyclic teardown.
+	StepDropRelations StepAction = "drop-relations"
+	// StepDelete deletes a blueprint.
+	StepDelete StepAction = "delete"
+)
+
+// Step is a single operation of a Plan.
+type Step struct {
+	Action    StepAction
+	Blueprint string
+	// Relations lists the relation identifiers added or dropped by
+	// StepAddRelations and StepDropRelations.
+	Relations []string
+}
+
+// String renders the step for logs and dry runs.
+func (s Step) String() string {
+	if len(s.Relations) > 0 {
+		return fmt.Sprintf("%s %s %v", s.Action, s.Blueprint, s.Relations)
+	}
+	return fmt.Sprintf("%s %s", s.Action, s.Blueprint)
+}
+
+// Plan orders a set of blueprints so that every relation target exists
+// before the blueprint pointing at it. Relations that close a cycle between
+// blueprints that do not exist yet are deferred: the blueprint is first
+// created without them and they are patched in once every blueprint exists.
+// Self-relations are never deferred, and blueprints that already exist are
+// updated with a single PUT of their full definition, since replacing a
+// blueprint without a relation deletes the relation and its entity data.
+// Relation targets outside the set are assumed to already exist.
+type Plan struct {
+	// Order lists blueprint identifiers in creation order.
+	Order []string
+	// Deferred maps a blueprint identifier to the relations created in the
+	// second pass, sorted by identifier.
+	Deferred map[string][]string
+
+	blueprints map[string]Blueprint
+	// payloads holds the raw definitions of plans built by NewRawPlan.
+	payloads map[string]map[string]any
+	// existing holds the blueprints known to exist, set by WithExisting.
+	existing map[string]bool
+}
+
+// NewPlan builds a creation plan for bps, assuming none of them exist yet;
+// Service.Apply adjusts it for the blueprints it finds (see WithExisting).
+// The order is deterministic: among blueprints that are ready at the same
+// time the smallest identifier wins. When every remaining blueprint waits
+// on another, the smallest identifier that lies on a cycle has the
+// relations closing its cycles deferred; its other relations are kept.
+func NewPlan(bps []Blueprint) (*Plan, error) {
+	p := &Plan{blueprints: make(map[string]Blueprint, len(bps))}
+	for _, bp := range bps {
+		if bp.Identifier == "" {
+			return nil, fmt.Errorf("blueprint identifier required")
+		}
+		if _, dup := p.blueprints[bp.Identifier]; dup {
+			return nil, fmt.Errorf("duplicate blueprint %q", bp.Identifier)
+		}
+		p.blueprints[bp.Identifier] = bp
+	}
+	p.order()
+	return p, nil
+}
+
+// WithExisting returns a copy of the plan for an organization where the
+// blueprints named in ids already exist. Relations targeting them are
+// satisfied, so they are never deferred, and they are updated in one
+// StepUpdate instead of created. Service.Apply calls it with the blueprints
+// it lists.
+func (p *Plan) WithExisting(ids []string) *Plan {
+	q := &Plan{blueprints: p.blueprints, payloads: p.payloads, existing: map[string]bool{}}
+	for _, id := range ids {
+		if _, ok := p.blueprints[id]; ok {
+			q.existing[id] = true
+		}
+	}
+	q.order()
+	return q
+}
+
+// order computes Order and Deferred.
+func (p *Plan) order() {
+	p.Order, p.Deferred = nil, map[string][]string{}
+	// deps[a][b] lists the relations of a that target b, for targets that
+	// still have to be created.
+	deps := map[string]map[string][]string{}
+	for id, bp := range p.blueprints {
+		deps[id] = map[string][]string{}
+		for relID, rel := range bp.Relations {
+			if _, ok := p.blueprints[rel.Target]; ok && rel.Target != id && !p.existing[rel.Target] {
+				deps[id][rel.Target] = append(deps[id][rel.Target], relID)
+			}
+		}
+	}
+
+	remaining := sortedIDs(p.blueprints)
+	for len(remaining) > 0 {
+		next := -1
+		for i, id := range remaining {
+			if len(deps[id]) == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			p.breakCycle(remaining, deps)
+			continue
+		}
+		id := remaining[next]
+		remaining = append(remaining[:next], remaining[next+1:]...)
+		p.Order = append(p.Order, id)
+		for _, other := range remaining {
+			delete(deps[other], id)
+		}
+	}
+	for id := range p.Deferred {
+		sort.Strings(p.Deferred[id])
+	}
+}
+
+// breakCycle defers, for the first remaining blueprint on a cycle, the
+// relations whose target leads back to it. Every remaining blueprint waits
+// on another, so such a blueprint exists.
+func (p *Plan) breakCycle(remaining []string, deps map[string]map[string][]string) {
+	for _, id := range remaining {
+		targets := make([]string, 0, len(deps[id]))
+		for target := range deps[id] {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+		broken := false
+		for _, target := range targets {
+			if reaches(deps, target, id) {
+				p.Deferred[id] = append(p.Deferred[id], deps[id][target]...)
+				delete(deps[id], target)
+				broken = true
+			}
+		}
+		if broken {
+			return
+		}
+	}
+}
+
+// reaches reports whether to can be reached from from along deps.
+func reaches(deps map[string]map[string][]string, from, to string) bool {
+	seen := map[string]bool{}
+	stack := []string{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == to {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		for next := range deps[id] {
+			stack = append(stack, next)
+		}
+	}
+	return false
+}
+
+// NewRawPlan is NewPlan for raw blueprint definitions, such as blueprints
+// read from a backup or merged onto a live definition. Apply sends the raw
+// definitions, so fields Blueprint does not model are preserved.
+func NewRawPlan(payloads []json.RawMessage) (*Plan, error) {
+	bps := make([]Blueprint, 0, len(payloads))
+	raw := make(map[string]map[string]any, len(payloads))
+	for _, data := range payloads {
+		var bp Blueprint
+		if err := json.Unmarshal(data, &bp); err != nil {
+			return nil, fmt.Errorf("decode blueprint: %w", err)
+		}
+		// Numbers are kept as json.Number so they are written back exactly.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("decode blueprint: %w", err)
+		}
+		bps = append(bps, bp)
+		raw[bp.Identifier] = m
+	}
+	p, err := NewPlan(bps)
+	if err != nil {
+		return nil, err
+	}
+	p.payloads = raw
+	return p, nil
+}
+
+// Blueprint returns the blueprint with the given identifier from the plan.
+func (p *Plan) Blueprint(identifier string) (Blueprint, bool) {
+	bp, ok := p.blueprints[identifier]
+	return bp, ok
+}
+
+// Steps returns the operations performed by Service.Apply on a plan
+// returned by WithExisting or Service.Resolve.
+func (p *Plan) Steps() []Step {
+	steps := make([]Step, 0, len(p.Order)+len(p.Deferred))
+	for _, id := range p.Order {
+		action := StepCreate
+		if p.existing[id] {
+			action = StepUpdate
+		}
+		steps = append(steps, Step{Action: action, Blueprint: id})
+	}
+	for _, id := range p.Order {
+		if rels := p.Deferred[id]; len(rels) > 0 {
+			steps = append(steps, Step{Action: StepAddRelations, Blueprint: id, Relations: rels})
+		}
+	}
+	return steps
+}
+
+// TeardownOrder returns blueprint identifiers in deletion order, the reverse
+// of Order.
+func (p *Plan) TeardownOrder() []string {
+	out := make([]string, len(p.Order))
+	for i, id := range p.Order {
+		out[len(p.Order)-1-i] = id
+	}
+	return out
+}
+
+// TeardownSteps returns the operations performed by Service.Teardown:
+// entities are deleted first, then deferred relations are dropped to break
+// cycles, then blueprints are deleted in reverse creation order.
+func (p *Plan) TeardownSteps() []Step {
+	order := p.TeardownOrder()
+	steps := make([]Step, 0, 2*len(order)+len(p.Deferred))
+	for _, id := range order {
+		steps = append(steps, Step{Action: StepDeleteEntities, Blueprint: id})
+	}
+	for _, id := range order {
+		if rels := p.Deferred[id]; len(rels) > 0 {
+			steps = append(steps, Step{Action: StepDropRelations, Blueprint: id, Relations: rels})
+		}
+	}
+	for _, id := range order {
+		steps = append(steps, Step{Action: StepDelete, Blueprint: id})
+	}
+	return steps
+}
+
+// withoutRelations returns bp minus the named relations.
+func (p *Plan) withoutRelations(id string, drop []string) Blueprint {
+	bp := p.blueprints[id]
+	if len(drop) == 0 {
+		return bp
+	}
+	skip := map[string]bool{}
+	for _, rel := range drop {
+		skip[rel] = true
+	}
+	rels := make(map[string]Relation, len(bp.Relations))
+	for relID, rel := range bp.Relations {
+		if !skip[relID] {
+			rels[relID] = rel
+		}
+	}
+	bp.Relations = rels
+	return bp
+}
+
+// body returns the definition of id minus the named relations, raw if the
+// plan has one.
+func (p *Plan) body(id string, drop []string) any {
+	raw, ok := p.payloads[id]
+	if !ok {
+		return p.withoutRelations(id, drop)
+	}
+	if len(drop) == 0 {
+		return raw
+	}
+	out := make(map[string]any, len(raw))
+	for k, v := range raw {
+		out[k] = v
+	}
+	out["relations"] = p.relations(id, drop)
+	return out
+}
+
+// relations returns the relations of id minus the named ones.
+func (p *Plan) relations(id string, drop []string) any {
+	raw, ok := p.payloads[id]
+	if !ok {
+		return p.withoutRelations(id, drop).Relations
+	}
+	rels, _ := raw["relations"].(map[string]any)
+	skip := map[string]bool{}
+	for _, rel := range drop {
+		skip[rel] = true
+	}
+	out := make(map[string]any, len(rels))
+	for relID, rel := range rels {
+		if !skip[relID] {
+			out[relID] = rel
+		}
+	}
+	return out
+}
+
+// Resolve lists the organization's blueprints and returns p adjusted for
+// the ones that already exist (see WithExisting), for dry runs that print
+// the steps Apply will perform.
+func (s *Service) Resolve(ctx context.Context, p *Plan) (*Plan, error) {
+	list, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(list))
+	for _, bp := range list {
+		ids = append(ids, bp.Identifier)
+	}
+	return p.WithExisting(ids), nil
+}
+
+// Apply creates or updates every blueprint of the plan in order. Existing
+// blueprints are updated with one PUT of their full definition. New
+// blueprints with deferred relations are created without them and patched
+// once all blueprints exist. It stops at the first failing step.
+func (s *Service) Apply(ctx context.Context, p *Plan) error {
+	p, err := s.Resolve(ctx, p)
+	if err != nil {
+		return fmt.Errorf("list blueprints: %w", err)
+	}
+	for _, step := range p.Steps() {
+		if err := s.runStep(ctx, p, step); err != nil {
+			return fmt.Errorf("%s: %w", step, err)
+		}
+	}
+	return nil
+}
+
+// Teardown deletes every entity of the planned blueprints and then the
+// blueprints themselves, dependents first. It stops at the first failing step.
+func (s *Service) Teardown(ctx context.Context, p *Plan) error {
+	for _, step := range p.TeardownSteps() {
+		if err := s.runStep(ctx, p, step); err != nil {
+			return fmt.Errorf("%s: %w", step, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) runStep(ctx context.Context, p *Plan, step Step) error {
+	path := fmt.Sprintf("/v1/blueprints/%s", url.PathEscape(step.Blueprint))
+	switch step.Action {
+	case StepCreate:
+		return s.doer.Do(ctx, "POST", "/v1/blueprints", p.body(step.Blueprint, p.Deferred[step.Blueprint]), nil)
+	case StepUpdate:
+		return s.doer.Do(ctx, "PUT", path, p.body(step.Blueprint, nil), nil)
+	case StepAddRelations:
+		body := map[string]any{"relations": p.relations(step.Blueprint, nil)}
+		return s.doer.Do(ctx, "PATCH", path, body, nil)
+	case StepDropRelations:
+		body := map[string]any{"relations": p.relations(step.Blueprint, step.Relations)}
+		return s.doer.Do(ctx, "PATCH", path, body, nil)
+	case StepDeleteEntities:
+		return s.DeleteAllEntities(ctx, step.Blueprint)
+	case StepDelete:
+		return s.Delete(ctx, step.Blueprint)
+	default:
+		return fmt.Errorf("unknown plan step %q", step.Action)
+	}
+}
+
+// DeleteAllEntities removes every entity of a blueprint.
+func (s *Service) DeleteAllEntities(ctx context.Context, identifier string) error {
+	path := fmt.Sprintf("/v1/blueprints/%s/all-entities", url.PathEscape(identifier))
+	return s.doer.Do(ctx, "DELETE", path, nil, nil)
+}
+
+func sortedIDs(m map[string]Blueprint) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
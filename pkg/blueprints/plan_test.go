@@ -0,0 +1,171 @@
+package blueprints
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type recordingDoer struct {
+	calls  []string
+	last   map[string]any
+	bodies []map[string]any
+	// existing is served by GET /v1/blueprints.
+	existing []Blueprint
+}
+
+func (r *recordingDoer) Do(ctx context.Context, method, path string, body any, out any) error {
+	if method == "GET" {
+		data, _ := json.Marshal(map[string]any{"blueprints": r.existing})
+		return json.Unmarshal(data, out)
+	}
+	r.calls = append(r.calls, method+" "+path)
+	if m, ok := body.(map[string]any); ok {
+		r.last = m
+	}
+	var generic map[string]any
+	data, _ := json.Marshal(body)
+	_ = json.Unmarshal(data, &generic)
+	r.bodies = append(r.bodies, generic)
+	return nil
+}
+
+func TestNewPlanOrdersDependencies(t *testing.T) {
+	plan, err := NewPlan([]Blueprint{
+		{Identifier: "service", Relations: map[string]Relation{"team": {Target: "team"}, "domain": {Target: "domain"}}},
+		{Identifier: "domain"},
+		{Identifier: "team", Relations: map[string]Relation{"org": {Target: "external"}}},
+	})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if want := []string{"domain", "team", "service"}; !reflect.DeepEqual(plan.Order, want) {
+		t.Fatalf("order = %v, want %v", plan.Order, want)
+	}
+	if len(plan.Deferred) != 0 {
+		t.Fatalf("unexpected deferred relations: %v", plan.Deferred)
+	}
+	if want := []string{"service", "team", "domain"}; !reflect.DeepEqual(plan.TeardownOrder(), want) {
+		t.Fatalf("teardown = %v", plan.TeardownOrder())
+	}
+	if _, err := NewPlan([]Blueprint{{Identifier: "a"}, {Identifier: "a"}}); err == nil {
+		t.Fatalf("expected duplicate error")
+	}
+}
+
+func TestPlanBreaksCycles(t *testing.T) {
+	plan, err := NewPlan([]Blueprint{
+		{Identifier: "b", Relations: map[string]Relation{"a": {Target: "a"}}},
+		{Identifier: "a", Relations: map[string]Relation{"b": {Target: "b"}, "parent": {Target: "a"}}},
+	})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(plan.Order, want) {
+		t.Fatalf("order = %v", plan.Order)
+	}
+	// The self-relation needs no deferring: a exists once it is created.
+	if want := map[string][]string{"a": {"b"}}; !reflect.DeepEqual(plan.Deferred, want) {
+		t.Fatalf("deferred = %v", plan.Deferred)
+	}
+
+	doer := &recordingDoer{}
+	if err := New(doer).Apply(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	want := []string{
+		"POST /v1/blueprints",
+		"POST /v1/blueprints",
+		"PATCH /v1/blueprints/a",
+	}
+	if !reflect.DeepEqual(doer.calls, want) {
+		t.Fatalf("apply calls = %v", doer.calls)
+	}
+	if rels := doer.last["relations"].(map[string]Relation); len(rels) != 2 {
+		t.Fatalf("expected both relations patched in, got %v", rels)
+	}
+
+	doer = &recordingDoer{}
+	if err := New(doer).Teardown(context.Background(), plan); err != nil {
+		t.Fatalf("teardown: %v", err)
+	}
+	want = []string{
+		"DELETE /v1/blueprints/b/all-entities",
+		"DELETE /v1/blueprints/a/all-entities",
+		"PATCH /v1/blueprints/a",
+		"DELETE /v1/blueprints/b",
+		"DELETE /v1/blueprints/a",
+	}
+	if !reflect.DeepEqual(doer.calls, want) {
+		t.Fatalf("teardown calls = %v", doer.calls)
+	}
+}
+
+func TestRawPlanKeepsUnmodeledFields(t *testing.T) {
+	plan, err := NewRawPlan([]json.RawMessage{
+		json.RawMessage(`{"identifier":"a","ownership":{"type":"Direct"},"relations":{"self":{"target":"a","many":false}}}`),
+	})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	doer := &recordingDoer{}
+	if err := New(doer).Apply(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if want := []string{"POST /v1/blueprints"}; !reflect.DeepEqual(doer.calls, want) {
+		t.Fatalf("calls = %v", doer.calls)
+	}
+	create := doer.bodies[0]
+	if _, ok := create["relations"].(map[string]any)["self"]; !ok || create["ownership"] == nil {
+		t.Fatalf("create body %v", create)
+	}
+}
+
+func TestPlanDefersOnlyCycleRelations(t *testing.T) {
+	// c only depends on the a<->b cycle; it sorts first but must not have
+	// its relation deferred.
+	plan, err := NewPlan([]Blueprint{
+		{Identifier: "a", Relations: map[string]Relation{"b": {Target: "b"}}},
+		{Identifier: "b", Relations: map[string]Relation{"a": {Target: "a"}}},
+		{Identifier: "0c", Relations: map[string]Relation{"a": {Target: "a"}}},
+	})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if want := map[string][]string{"a": {"b"}}; !reflect.DeepEqual(plan.Deferred, want) {
+		t.Fatalf("deferred = %v", plan.Deferred)
+	}
+	if want := []string{"a", "0c", "b"}; !reflect.DeepEqual(plan.Order, want) {
+		t.Fatalf("order = %v", plan.Order)
+	}
+}
+
+func TestApplyUpdatesExistingBlueprintsInOnePut(t *testing.T) {
+	plan, err := NewRawPlan([]json.RawMessage{
+		json.RawMessage(`{"identifier":"team","relations":{"parent":{"target":"team"},"lead":{"target":"service"}}}`),
+		json.RawMessage(`{"identifier":"service","relations":{"team":{"target":"team"}}}`),
+	})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	doer := &recordingDoer{existing: []Blueprint{{Identifier: "team"}, {Identifier: "service"}}}
+	if err := New(doer).Apply(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if want := []string{"PUT /v1/blueprints/service", "PUT /v1/blueprints/team"}; !reflect.DeepEqual(doer.calls, want) {
+		t.Fatalf("calls = %v", doer.calls)
+	}
+	if rels := doer.bodies[1]["relations"].(map[string]any); len(rels) != 2 {
+		t.Fatalf("existing blueprint sent without its relations: %v", rels)
+	}
+
+	// Only team exists: service is created first, team is updated in full.
+	doer = &recordingDoer{existing: []Blueprint{{Identifier: "team"}}}
+	if err := New(doer).Apply(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if want := []string{"POST /v1/blueprints", "PUT /v1/blueprints/team"}; !reflect.DeepEqual(doer.calls, want) {
+		t.Fatalf("calls = %v", doer.calls)
+	}
+}
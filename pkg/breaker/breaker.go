@@ -0,0 +1,313 @@
+// Package breaker provides a circuit breaker used by the client to fail fast
+// while the Port API is degraded. Each route group has its own circuit,
+// which opens after consecutive failures or a high error rate, rejects
+// requests with ErrCircuitOpen while open, and lets trial requests through
+// (half-open) once a cool-down has passed.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+)
+
+// State is the state of a circuit.
+type State int
+
+// Circuit states.
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// ErrCircuitOpen is matched (with errors.Is) by the errors returned while a
+// circuit rejects requests.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// OpenError reports a rejected request.
+type OpenError struct {
+	Group string
+	// Until is when the circuit lets a trial request through.
+	Until time.Time
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open for %s until %s", e.Group, e.Until.Format(time.RFC3339))
+}
+
+// Is makes errors.Is(err, ErrCircuitOpen) true.
+func (e *OpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// Options configure a Breaker. Zero fields use the defaults noted below.
+type Options struct {
+	// ConsecutiveFailures opens a circuit after this many failures in a row.
+	// Defaults to 5.
+	ConsecutiveFailures int
+	// FailureRate opens a circuit when the share of failed requests within
+	// Window reaches it (for example 0.5). Zero disables rate tripping.
+	FailureRate float64
+	// MinRequests is the number of requests within Window before
+	// FailureRate applies. Defaults to 20.
+	MinRequests int
+	// Window is the period over which FailureRate is measured; counts
+	// reset at the end of each window. Defaults to one minute.
+	Window time.Duration
+	// OpenTimeout is how long a circuit stays open before letting trial
+	// requests through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of trial requests that must succeed
+	// to close the circuit again. Defaults to 1.
+	HalfOpenRequests int
+	// GroupBy assigns requests to circuits. Defaults to DefaultGroup.
+	GroupBy func(req *http.Request) string
+	// IsFailure classifies an outcome. Defaults to IsFailure.
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange is called after a circuit changes state. It must not
+	// block.
+	OnStateChange func(group string, from, to State)
+}
+
+// DefaultGroup groups requests by API area: entity routes under
+// /v1/blueprints/{id}/entities form the "entities" group, everything else is
+// grouped by its first path segment after /v1 ("blueprints", "actions", ...).
+func DefaultGroup(req *http.Request) string {
+	segs := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segs) > 0 && segs[0] == "v1" {
+		segs = segs[1:]
+	}
+	if len(segs) >= 3 && segs[0] == "blueprints" && segs[2] == "entities" {
+		return "entities"
+	}
+	if len(segs) == 0 || segs[0] == "" {
+		return "default"
+	}
+	return segs[0]
+}
+
+// IsFailure counts transport errors (except context cancellation) and 5xx
+// responses as failures. 429 is left to the rate limiter and retries. An
+// *httpx.RetryExhaustedError is judged by the last response it describes,
+// so retries that ran out on 429 do not open the circuit.
+func IsFailure(resp *http.Response, err error) bool {
+	var exhausted *httpx.RetryExhaustedError
+	if errors.As(err, &exhausted) && exhausted.StatusCode != 0 {
+		return exhausted.StatusCode >= 500
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode >= 500
+}
+
+// Breaker tracks one circuit per group. It is safe for concurrent use.
+type Breaker struct {
+	opts Options
+	now  func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state State
+	// generation changes on every state change so outcomes of requests
+	// admitted in an earlier state are ignored.
+	generation  uint64
+	consecutive int
+	requests    int
+	failures    int
+	windowEnd   time.Time
+	openUntil   time.Time
+	trials      int // trial requests admitted while half-open
+	successes   int // trial requests that succeeded
+}
+
+// New returns a breaker with every circuit closed.
+func New(opts Options) *Breaker {
+	if opts.ConsecutiveFailures <= 0 {
+		opts.ConsecutiveFailures = 5
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = 20
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 30 * time.Second
+	}
+	if opts.HalfOpenRequests <= 0 {
+		opts.HalfOpenRequests = 1
+	}
+	if opts.GroupBy == nil {
+		opts.GroupBy = DefaultGroup
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = IsFailure
+	}
+	return &Breaker{opts: opts, now: time.Now, circuits: map[string]*circuit{}}
+}
+
+// Allow admits a request in group or returns an *OpenError. When admitted,
+// done must be called with whether the request failed.
+func (b *Breaker) Allow(group string) (done func(failed bool), err error) {
+	b.mu.Lock()
+	now := b.now()
+	c := b.circuit(group, now)
+	var changes []change
+	if c.state == StateOpen && !now.Before(c.openUntil) {
+		changes = append(changes, b.transition(group, c, StateHalfOpen, now))
+	}
+	switch {
+	case c.state == StateOpen:
+		err = &OpenError{Group: group, Until: c.openUntil}
+	case c.state == StateHalfOpen && c.trials >= b.opts.HalfOpenRequests:
+		err = &OpenError{Group: group, Until: now}
+	case c.state == StateHalfOpen:
+		c.trials++
+	}
+	gen := c.generation
+	b.mu.Unlock()
+	b.notify(changes)
+	if err != nil {
+		return nil, err
+	}
+	return func(failed bool) { b.record(group, gen, failed) }, nil
+}
+
+// Do runs the request through the circuit of its group.
+func (b *Breaker) Do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	done, err := b.Allow(b.opts.GroupBy(req))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := send(req)
+	done(b.opts.IsFailure(resp, err))
+	return resp, err
+}
+
+// State returns the state of group's circuit.
+func (b *Breaker) State(group string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[group]; ok {
+		if c.state == StateOpen && !b.now().Before(c.openUntil) {
+			return StateHalfOpen
+		}
+		return c.state
+	}
+	return StateClosed
+}
+
+// States returns the state of every circuit that has seen a request.
+func (b *Breaker) States() map[string]State {
+	b.mu.Lock()
+	groups := make([]string, 0, len(b.circuits))
+	for g := range b.circuits {
+		groups = append(groups, g)
+	}
+	b.mu.Unlock()
+	out := make(map[string]State, len(groups))
+	for _, g := range groups {
+		out[g] = b.State(g)
+	}
+	return out
+}
+
+func (b *Breaker) record(group string, gen uint64, failed bool) {
+	b.mu.Lock()
+	now := b.now()
+	c := b.circuit(group, now)
+	var changes []change
+	if c.generation == gen {
+		switch c.state {
+		case StateHalfOpen:
+			if failed {
+				changes = append(changes, b.transition(group, c, StateOpen, now))
+			} else if c.successes++; c.successes >= b.opts.HalfOpenRequests {
+				changes = append(changes, b.transition(group, c, StateClosed, now))
+			}
+		case StateClosed:
+			c.requests++
+			if failed {
+				c.failures++
+				c.consecutive++
+			} else {
+				c.consecutive = 0
+			}
+			if c.consecutive >= b.opts.ConsecutiveFailures || b.rateExceeded(c) {
+				changes = append(changes, b.transition(group, c, StateOpen, now))
+			}
+		}
+	}
+	b.mu.Unlock()
+	b.notify(changes)
+}
+
+func (b *Breaker) rateExceeded(c *circuit) bool {
+	return b.opts.FailureRate > 0 && c.requests >= b.opts.MinRequests &&
+		float64(c.failures)/float64(c.requests) >= b.opts.FailureRate
+}
+
+// circuit returns group's circuit, resetting its window counters if the
+// window has ended.
+func (b *Breaker) circuit(group string, now time.Time) *circuit {
+	c, ok := b.circuits[group]
+	if !ok {
+		c = &circuit{}
+		b.circuits[group] = c
+	}
+	if !now.Before(c.windowEnd) {
+		c.requests, c.failures = 0, 0
+		c.windowEnd = now.Add(b.opts.Window)
+	}
+	return c
+}
+
+type change struct {
+	group    string
+	from, to State
+}
+
+func (b *Breaker) transition(group string, c *circuit, to State, now time.Time) change {
+	ch := change{group: group, from: c.state, to: to}
+	c.state = to
+	c.generation++
+	c.consecutive, c.requests, c.failures = 0, 0, 0
+	c.windowEnd = now.Add(b.opts.Window)
+	c.trials, c.successes = 0, 0
+	if to == StateOpen {
+		c.openUntil = now.Add(b.opts.OpenTimeout)
+	}
+	return ch
+}
+
+func (b *Breaker) notify(changes []change) {
+	if b.opts.OnStateChange == nil {
+		return
+	}
+	for _, ch := range changes {
+		b.opts.OnStateChange(ch.group, ch.from, ch.to)
+	}
+}
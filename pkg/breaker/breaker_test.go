@@ -0,0 +1,134 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+)
+
+func newTestBreaker(opts Options) (*Breaker, *time.Time, *[]string) {
+	var changes []string
+	opts.OnStateChange = func(group string, from, to State) {
+		changes = append(changes, fmt.Sprintf("%s:%s->%s", group, from, to))
+	}
+	b := New(opts)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	return b, &now, &changes
+}
+
+func call(t *testing.T, b *Breaker, failed bool) error {
+	t.Helper()
+	done, err := b.Allow("entities")
+	if err != nil {
+		return err
+	}
+	done(failed)
+	return nil
+}
+
+func TestBreakerLifecycle(t *testing.T) {
+	b, now, changes := newTestBreaker(Options{ConsecutiveFailures: 3, OpenTimeout: 10 * time.Second})
+	for i := 0; i < 3; i++ {
+		if err := call(t, b, true); err != nil {
+			t.Fatalf("call %d rejected: %v", i, err)
+		}
+	}
+	err := call(t, b, false)
+	var open *OpenError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &open) || open.Group != "entities" {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+	if b.State("blueprints") != StateClosed {
+		t.Fatal("other groups must stay closed")
+	}
+
+	*now = now.Add(10 * time.Second)
+	done, err := b.Allow("entities")
+	if err != nil {
+		t.Fatalf("trial rejected: %v", err)
+	}
+	if _, err := b.Allow("entities"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("only one trial request should be admitted")
+	}
+	done(false)
+	if b.State("entities") != StateClosed {
+		t.Fatalf("state %s after successful trial", b.State("entities"))
+	}
+	want := []string{"entities:closed->open", "entities:open->half-open", "entities:half-open->closed"}
+	if !reflect.DeepEqual(*changes, want) {
+		t.Fatalf("changes %v", *changes)
+	}
+}
+
+func TestBreakerFailureRate(t *testing.T) {
+	b, _, _ := newTestBreaker(Options{ConsecutiveFailures: 100, FailureRate: 0.5, MinRequests: 4})
+	for _, failed := range []bool{true, false, true, false} {
+		if err := call(t, b, failed); err != nil {
+			t.Fatalf("rejected early: %v", err)
+		}
+	}
+	if b.State("entities") != StateOpen {
+		t.Fatalf("expected open at 50%% failures, got %s", b.State("entities"))
+	}
+}
+
+func TestBreakerIgnoresStaleOutcomes(t *testing.T) {
+	b, _, _ := newTestBreaker(Options{ConsecutiveFailures: 1})
+	slow, _ := b.Allow("entities")
+	if err := call(t, b, true); err != nil {
+		t.Fatal(err)
+	}
+	slow(false) // admitted while closed; must not affect the open circuit
+	if b.State("entities") != StateOpen {
+		t.Fatal("stale success closed the circuit")
+	}
+}
+
+func TestDefaultGroup(t *testing.T) {
+	cases := map[string]string{
+		"/v1/blueprints/service/entities/search": "entities",
+		"/v1/blueprints/service":                 "blueprints",
+		"/v1/actions":                            "actions",
+		"/":                                      "default",
+	}
+	for path, want := range cases {
+		if got := DefaultGroup(httptest.NewRequest(http.MethodGet, path, nil)); got != want {
+			t.Errorf("DefaultGroup(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestIsFailure(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		err    error
+		want   bool
+	}{
+		{"ok", http.StatusOK, nil, false},
+		{"server error", http.StatusBadGateway, nil, true},
+		{"throttled", http.StatusTooManyRequests, nil, false},
+		{"transport", 0, errors.New("connection reset"), true},
+		{"canceled", 0, fmt.Errorf("send: %w", context.Canceled), false},
+		{"exhausted on 5xx", 0, &httpx.RetryExhaustedError{Attempts: 3, StatusCode: http.StatusServiceUnavailable}, true},
+		{"exhausted on 429", 0, fmt.Errorf("port: %w", &httpx.RetryExhaustedError{Attempts: 3, StatusCode: http.StatusTooManyRequests}), false},
+		{"exhausted on transport", 0, &httpx.RetryExhaustedError{Attempts: 3, Err: errors.New("timeout")}, true},
+	}
+	for _, tc := range cases {
+		var resp *http.Response
+		if tc.err == nil {
+			resp = &http.Response{StatusCode: tc.status}
+		}
+		if got := IsFailure(resp, tc.err); got != tc.want {
+			t.Errorf("%s: IsFailure = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
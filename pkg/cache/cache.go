@@ -0,0 +1,224 @@
+// Package cache provides a small TTL + LRU cache with request coalescing used
+// by the client to cache read-heavy Port API calls.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configure a Cache.
+type Options struct {
+	// TTL is how long an entry stays fresh. Defaults to 30 seconds.
+	TTL time.Duration
+	// MaxEntries bounds the cache size; the least recently used entry is
+	// evicted first. Defaults to 1000.
+	MaxEntries int
+	// LoadTimeout bounds a shared load, which outlives the context of the
+	// caller that started it. Defaults to 30 seconds.
+	LoadTimeout time.Duration
+}
+
+// Stats reports cache effectiveness counters.
+type Stats struct {
+	Hits          uint64
+	Misses        uint64
+	Coalesced     uint64 // misses served by another in-flight load
+	Evictions     uint64
+	Invalidations uint64
+	Size          int
+}
+
+// Cache is a string-keyed TTL cache with LRU eviction. Concurrent misses for
+// the same key are coalesced into a single load. It is safe for concurrent use.
+type Cache[V any] struct {
+	ttl         time.Duration
+	maxEntries  int
+	loadTimeout time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	ll       *list.List
+	items    map[string]*list.Element
+	inflight map[string]*call[V]
+	gen      uint64
+	stats    Stats
+}
+
+type entry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// New returns an empty cache.
+func New[V any](opts Options) *Cache[V] {
+	if opts.TTL <= 0 {
+		opts.TTL = 30 * time.Second
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1000
+	}
+	if opts.LoadTimeout <= 0 {
+		opts.LoadTimeout = 30 * time.Second
+	}
+	return &Cache[V]{
+		ttl:         opts.TTL,
+		maxEntries:  opts.MaxEntries,
+		loadTimeout: opts.LoadTimeout,
+		now:         time.Now,
+		ll:          list.New(),
+		items:       map[string]*list.Element{},
+		inflight:    map[string]*call[V]{},
+	}
+}
+
+// Get returns the cached value for key, calling load on a miss. Only one load
+// per key runs at a time; concurrent callers wait for its result. Each caller
+// stops waiting when its own ctx is done, while the load itself runs with
+// ctx's values but without its cancellation, bounded by Options.LoadTimeout,
+// so one canceled caller does not fail the others. Errors are not cached. A
+// value loaded while the key was invalidated is returned to the waiting
+// callers but not stored.
+func (c *Cache[V]) Get(ctx context.Context, key string, load func(context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		ent := el.Value.(*entry[V])
+		if c.now().Before(ent.expires) {
+			c.ll.MoveToFront(el)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return ent.value, nil
+		}
+		c.removeElement(el)
+	}
+	c.stats.Misses++
+	cl, ok := c.inflight[key]
+	if ok {
+		c.stats.Coalesced++
+	} else {
+		cl = &call[V]{done: make(chan struct{})}
+		c.inflight[key] = cl
+		go c.load(ctx, key, cl, c.gen, load)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// load runs a shared load and publishes its result to cl.
+func (c *Cache[V]) load(ctx context.Context, key string, cl *call[V], gen uint64, load func(context.Context) (V, error)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.loadTimeout)
+	defer cancel()
+	cl.value, cl.err = load(ctx)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if cl.err == nil && gen == c.gen {
+		c.store(key, cl.value)
+	}
+	c.mu.Unlock()
+	close(cl.done)
+}
+
+// Peek returns a fresh cached value without loading or touching LRU order.
+func (c *Cache[V]) Peek(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	ent := el.Value.(*entry[V])
+	if !c.now().Before(ent.expires) {
+		return zero, false
+	}
+	return ent.value, true
+}
+
+// Set stores a value, replacing any existing entry.
+func (c *Cache[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(key, value)
+}
+
+// Invalidate removes key from the cache.
+func (c *Cache[V]) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.stats.Invalidations++
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// InvalidatePrefix removes every key starting with prefix.
+func (c *Cache[V]) InvalidatePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.stats.Invalidations++
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(el)
+		}
+	}
+}
+
+// Purge removes all entries.
+func (c *Cache[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.stats.Invalidations++
+	c.ll.Init()
+	c.items = map[string]*list.Element{}
+}
+
+// Stats returns a snapshot of the counters.
+func (c *Cache[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Size = c.ll.Len()
+	return s
+}
+
+func (c *Cache[V]) store(key string, value V) {
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		ent := el.Value.(*entry[V])
+		ent.value = value
+		ent.expires = expires
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&entry[V]{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache[V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[V]).key)
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheTTLAndLRU(t *testing.T) {
+	ctx := context.Background()
+	c := New[int](Options{TTL: time.Minute, MaxEntries: 2})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	loads := 0
+	load := func(v int) func(context.Context) (int, error) {
+		return func(context.Context) (int, error) { loads++; return v, nil }
+	}
+	c.Get(ctx, "a", load(1))
+	c.Get(ctx, "b", load(2))
+	if v, _ := c.Get(ctx, "a", load(99)); v != 1 {
+		t.Fatalf("expected cached a, got %d", v)
+	}
+	c.Get(ctx, "c", load(3)) // evicts b, the least recently used
+	if _, ok := c.Peek("b"); ok {
+		t.Fatalf("expected b to be evicted")
+	}
+	now = now.Add(2 * time.Minute)
+	if v, _ := c.Get(ctx, "a", load(10)); v != 10 {
+		t.Fatalf("expected expired a to reload, got %d", v)
+	}
+	s := c.Stats()
+	if s.Hits != 1 || s.Misses != 4 || s.Evictions != 1 || s.Size != 2 || loads != 4 {
+		t.Fatalf("unexpected stats %+v loads=%d", s, loads)
+	}
+}
+
+func TestCacheCoalescesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	c := New[string](Options{})
+	var loads int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Get(ctx, "k", func(context.Context) (string, error) {
+				atomic.AddInt32(&loads, 1)
+				<-release
+				return "v", nil
+			})
+			if err != nil || v != "v" {
+				t.Errorf("got %q %v", v, err)
+			}
+		}()
+	}
+	for c.Stats().Misses < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if loads != 1 {
+		t.Fatalf("expected a single load, got %d", loads)
+	}
+	if s := c.Stats(); s.Coalesced != 9 {
+		t.Fatalf("expected 9 coalesced misses, got %+v", s)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	c := New[int](Options{})
+	c.Set("/v1/blueprints/a/entities/x", 1)
+	c.Set("/v1/blueprints/a/entities/y", 2)
+	c.Set("/v1/blueprints/b", 3)
+	c.InvalidatePrefix("/v1/blueprints/a/")
+	if _, ok := c.Peek("/v1/blueprints/a/entities/x"); ok {
+		t.Fatalf("prefix invalidation missed x")
+	}
+	if _, ok := c.Peek("/v1/blueprints/b"); !ok {
+		t.Fatalf("unrelated key invalidated")
+	}
+
+	// A load racing with an invalidation must not repopulate the cache.
+	_, _ = c.Get(ctx, "k", func(context.Context) (int, error) {
+		c.Invalidate("k")
+		return 1, nil
+	})
+	if _, ok := c.Peek("k"); ok {
+		t.Fatalf("stale load was stored")
+	}
+	if _, err := c.Get(ctx, "e", func(context.Context) (int, error) { return 0, errors.New("boom") }); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, ok := c.Peek("e"); ok {
+		t.Fatalf("errors must not be cached")
+	}
+}
+
+func TestCacheWaitersOutliveCanceledCaller(t *testing.T) {
+	c := New[string](Options{})
+	release := make(chan struct{})
+	first, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.Get(first, "k", func(ctx context.Context) (string, error) {
+			<-release
+			return "v", ctx.Err()
+		})
+		errs <- err
+	}()
+	for c.Stats().Misses < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	result := make(chan string, 1)
+	go func() {
+		v, err := c.Get(context.Background(), "k", func(context.Context) (string, error) {
+			t.Error("coalesced caller should not load")
+			return "", nil
+		})
+		if err != nil {
+			t.Errorf("waiter: %v", err)
+		}
+		result <- v
+	}()
+	for c.Stats().Coalesced < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled caller: %v", err)
+	}
+	close(release)
+	if v := <-result; v != "v" {
+		t.Fatalf("waiter got %q", v)
+	}
+	if v, ok := c.Peek("k"); !ok || v != "v" {
+		t.Fatalf("load not stored: %q %v", v, ok)
+	}
+}
@@ -0,0 +1,242 @@
+// Package catalog applies declarative definitions of blueprints, scorecards,
+// actions, webhooks, pages and integration configs to a Port organization
+// ("catalog as code"). Definitions are loaded from files, diffed against the live
+// organization into a Plan and applied in dependency order.
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/port-experimental/port-go-sdk/pkg/datasources"
+)
+
+// Doer matches client.Client.
+type Doer interface {
+	Do(ctx context.Context, method, path string, body any, out any) error
+}
+
+// Kind identifies a resource type.
+type Kind string
+
+// Supported resource kinds.
+const (
+	KindBlueprint   Kind = "blueprint"
+	KindAction      Kind = "action"
+	KindWebhook     Kind = "webhook"
+	KindIntegration Kind = "integration"
+	KindScorecard   Kind = "scorecard"
+	KindPage        Kind = "page"
+)
+
+// Resource is a single desired definition.
+type Resource struct {
+	Kind Kind
+	// ID is the resource identifier. Scorecard identifiers are only unique
+	// per blueprint, so their ID is "<blueprint>/<identifier>".
+	ID string
+	// Source is the file the resource was loaded from.
+	Source string
+	// Spec is the API payload as a map[string]any, so fields the SDK does
+	// not model are compared and written as declared. Integrations are the
+	// exception: their Spec is an IntegrationSpec. A scorecard's payload
+	// names its blueprint in the "blueprint" field.
+	Spec any
+}
+
+// MarshalJSON encodes the resource in the file format read by LoadFS, so
+// exported resources can be written back as definitions.
+func (r Resource) MarshalJSON() ([]byte, error) {
+	spec, err := json.Marshal(r.Spec)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(document{Kind: r.Kind, Spec: spec})
+}
+
+// IntegrationSpec is the desired configuration of an installed integration.
+// Integrations are installed outside Port's API, so apply only updates their
+// mapping config and never creates or prunes them.
+type IntegrationSpec struct {
+	Identifier string                        `json:"identifier"`
+	Config     datasources.IntegrationConfig `json:"config"`
+}
+
+// document is the on-disk envelope of a resource.
+type document struct {
+	Kind Kind            `json:"kind"`
+	Spec json.RawMessage `json:"spec"`
+}
+
+// Decoder converts a file in another format (for example YAML) into a value,
+// with the same semantics as json.Unmarshal.
+type Decoder func(data []byte, v any) error
+
+// LoadOptions configure LoadFS and LoadDir.
+type LoadOptions struct {
+	// Decoders maps additional file extensions (including the dot) to
+	// decoders. ".json" is always supported; register ".yaml" with a YAML
+	// library's Unmarshal to load YAML definitions.
+	Decoders map[string]Decoder
+}
+
+// LoadDir loads every definition file under dir.
+func LoadDir(dir string, opts *LoadOptions) ([]Resource, error) {
+	return LoadFS(os.DirFS(dir), opts)
+}
+
+// LoadFS loads every definition file in fsys. Each file holds one document
+// ({"kind": "blueprint", "spec": {...}}) or an array of documents. Files with
+// unknown extensions are skipped. Resources are returned sorted by kind and
+// identifier; duplicate identifiers within a kind are rejected.
+func LoadFS(fsys fs.FS, opts *LoadOptions) ([]Resource, error) {
+	decoders := map[string]Decoder{".json": unmarshalJSON}
+	if opts != nil {
+		for ext, dec := range opts.Decoders {
+			decoders[strings.ToLower(ext)] = dec
+		}
+	}
+	var out []Resource
+	seen := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		decode, ok := decoders[strings.ToLower(path.Ext(name))]
+		if !ok {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		docs, err := decodeDocuments(decode, data)
+		if err != nil {
+			return fmt.Errorf("catalog: %s: %w", name, err)
+		}
+		for i, doc := range docs {
+			res, err := decodeResource(doc)
+			if err != nil {
+				return fmt.Errorf("catalog: %s: document %d: %w", name, i, err)
+			}
+			res.Source = name
+			key := string(res.Kind) + "/" + res.ID
+			if prev, dup := seen[key]; dup {
+				return fmt.Errorf("catalog: %s %q defined in both %s and %s", res.Kind, res.ID, prev, name)
+			}
+			seen[key] = name
+			out = append(out, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortResources(out)
+	return out, nil
+}
+
+// decodeDocuments accepts a single document or an array of documents. Other
+// formats are decoded into generic values first and re-encoded as JSON so
+// every decoder shares the JSON field mapping.
+func decodeDocuments(decode Decoder, data []byte) ([]document, error) {
+	var generic any
+	if err := decode(data, &generic); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(generic)
+	if err != nil {
+		return nil, err
+	}
+	if _, isList := generic.([]any); isList {
+		var docs []document
+		err := json.Unmarshal(raw, &docs)
+		return docs, err
+	}
+	var doc document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return []document{doc}, nil
+}
+
+func decodeResource(doc document) (Resource, error) {
+	res := Resource{Kind: Kind(strings.ToLower(string(doc.Kind)))}
+	if len(doc.Spec) == 0 {
+		return res, fmt.Errorf("missing spec")
+	}
+	var err error
+	switch res.Kind {
+	case KindBlueprint, KindAction, KindWebhook, KindPage:
+		var spec map[string]any
+		if spec, err = decodeMap(doc.Spec); err == nil {
+			res.ID, _ = spec["identifier"].(string)
+			res.Spec = spec
+		}
+	case KindIntegration:
+		var spec IntegrationSpec
+		err = json.Unmarshal(doc.Spec, &spec)
+		res.ID, res.Spec = spec.Identifier, spec
+	case KindScorecard:
+		var spec map[string]any
+		if spec, err = decodeMap(doc.Spec); err == nil {
+			bp, _ := spec["blueprint"].(string)
+			id, _ := spec["identifier"].(string)
+			if bp == "" {
+				return res, fmt.Errorf("scorecard blueprint required")
+			}
+			if id != "" {
+				res.ID = bp + "/" + id
+			}
+			res.Spec = spec
+		}
+	default:
+		return res, fmt.Errorf("unknown kind %q", doc.Kind)
+	}
+	if err != nil {
+		return res, err
+	}
+	if res.ID == "" {
+		return res, fmt.Errorf("%s identifier required", res.Kind)
+	}
+	return res, nil
+}
+
+// unmarshalJSON is json.Unmarshal keeping numbers as json.Number, so large
+// integers in definitions survive being re-encoded.
+func unmarshalJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// decodeMap decodes a JSON object, keeping numbers as json.Number so they
+// are compared and written back exactly.
+func decodeMap(data []byte) (map[string]any, error) {
+	var m map[string]any
+	if err := unmarshalJSON(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// kindRank orders kinds by dependency: scorecards, actions and webhooks
+// reference blueprints, so blueprints come first, and page widgets may
+// reference any of them, so pages come last.
+var kindRank = map[Kind]int{KindBlueprint: 0, KindScorecard: 1, KindIntegration: 2, KindWebhook: 3, KindAction: 4, KindPage: 5}
+
+func sortResources(res []Resource) {
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Kind != res[j].Kind {
+			return kindRank[res[i].Kind] < kindRank[res[j].Kind]
+		}
+		return res[i].ID < res[j].ID
+	})
+}
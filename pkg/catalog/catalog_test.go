@@ -0,0 +1,285 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/port-experimental/port-go-sdk/pkg/blueprints"
+)
+
+// fakeOrg serves canned GET responses and records every write.
+type fakeOrg struct {
+	gets   map[string]any
+	writes []string
+	bodies map[string]any
+}
+
+func (f *fakeOrg) Do(ctx context.Context, method, path string, body any, out any) error {
+	if method == "GET" {
+		route, _, _ := strings.Cut(path, "?")
+		if resp, ok := f.gets[route]; ok && out != nil {
+			data, _ := json.Marshal(resp)
+			return json.Unmarshal(data, out)
+		}
+		return nil
+	}
+	f.writes = append(f.writes, method+" "+path)
+	if f.bodies == nil {
+		f.bodies = map[string]any{}
+	}
+	f.bodies[method+" "+path] = body
+	return nil
+}
+
+var testFS = fstest.MapFS{
+	"blueprints/service.json": {Data: []byte(`{"kind":"blueprint","spec":{"identifier":"service","title":"Service","schema":{"properties":{"tier":{"type":"string"}}},"relations":{"team":{"title":"Team","target":"team"}}}}`)},
+	"blueprints/team.json":    {Data: []byte(`{"kind":"blueprint","spec":{"identifier":"team","title":"Team","schema":{"properties":{}}}}`)},
+	"actions.json":            {Data: []byte(`[{"kind":"action","spec":{"identifier":"deploy","title":"Deploy","trigger":{"type":"self-service"}}}]`)},
+	"README.md":               {Data: []byte("ignored")},
+}
+
+func TestLoadFS(t *testing.T) {
+	res, err := LoadFS(testFS, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var ids []string
+	for _, r := range res {
+		ids = append(ids, string(r.Kind)+"/"+r.ID)
+	}
+	if want := []string{"blueprint/service", "blueprint/team", "action/deploy"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("loaded %v", ids)
+	}
+	bad := fstest.MapFS{"s.json": {Data: []byte(`{"kind":"scorecard","spec":{"identifier":"x"}}`)}}
+	if _, err := LoadFS(bad, nil); err == nil || !strings.Contains(err.Error(), "blueprint required") {
+		t.Fatalf("expected missing blueprint error, got %v", err)
+	}
+	bad = fstest.MapFS{"d.json": {Data: []byte(`{"kind":"dashboard","spec":{"identifier":"x"}}`)}}
+	if _, err := LoadFS(bad, nil); err == nil || !strings.Contains(err.Error(), "unknown kind") {
+		t.Fatalf("expected unknown kind error, got %v", err)
+	}
+	custom := fstest.MapFS{"a.txt": {Data: []byte("team")}}
+	res, err = LoadFS(custom, &LoadOptions{Decoders: map[string]Decoder{".txt": func(data []byte, v any) error {
+		*(v.(*any)) = map[string]any{"kind": "blueprint", "spec": map[string]any{"identifier": string(data)}}
+		return nil
+	}}})
+	if err != nil || len(res) != 1 || res[0].ID != "team" {
+		t.Fatalf("custom decoder: %v %+v", err, res)
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	res, err := LoadFS(testFS, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	org := &fakeOrg{gets: map[string]any{
+		"/v1/blueprints": map[string]any{"blueprints": []any{
+			// Unchanged apart from server-side fields and the managed label.
+			map[string]any{"identifier": "team", "title": "Team", "description": " [managed-by:ci run:old]", "schema": map[string]any{"properties": map[string]any{}, "required": []any{}}, "createdAt": "2024-01-01"},
+			// Managed but no longer defined: pruned.
+			map[string]any{"identifier": "legacy", "title": "Legacy", "description": "old [managed-by:ci run:old]"},
+			// Not managed by this owner: kept.
+			map[string]any{"identifier": "manual", "title": "Manual"},
+			map[string]any{"identifier": "service", "title": "Service", "schema": map[string]any{"properties": map[string]any{"tier": map[string]any{"type": "number"}}}, "ownership": map[string]any{"type": "Inherited"}, "updatedAt": "2024-01-01"},
+		}},
+		"/v1/actions":  map[string]any{"actions": []any{}},
+		"/v1/webhooks": map[string]any{"webhooks": []any{}},
+	}}
+	eng := New(org, &Options{ManagedBy: "ci", RunID: "run-1", Prune: true})
+	plan, err := eng.Plan(context.Background(), res)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	var summary []string
+	for _, c := range plan.Changes {
+		summary = append(summary, string(c.Action)+" "+string(c.Kind)+"/"+c.ID)
+	}
+	want := []string{"update blueprint/service", "create action/deploy", "delete blueprint/legacy"}
+	if !reflect.DeepEqual(summary, want) || plan.Unchanged != 1 {
+		t.Fatalf("plan = %v unchanged=%d\n%s", summary, plan.Unchanged, plan)
+	}
+	if !plan.Changes[0].Breaking || !strings.Contains(plan.String(), "type changed from number to string") {
+		t.Fatalf("expected breaking type change:\n%s", plan)
+	}
+	if err := eng.Apply(context.Background(), plan); err == nil {
+		t.Fatalf("expected breaking change to be refused")
+	}
+	if len(org.writes) != 0 {
+		t.Fatalf("refused apply wrote %v", org.writes)
+	}
+
+	eng.opts.AllowBreaking = true
+	if err := eng.Apply(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	wantWrites := []string{"PUT /v1/blueprints/service", "POST /v1/actions", "DELETE /v1/blueprints/legacy"}
+	if !reflect.DeepEqual(org.writes, wantWrites) {
+		t.Fatalf("writes = %v", org.writes)
+	}
+	bp := org.bodies["PUT /v1/blueprints/service"].(map[string]any)
+	if owner, run, _ := parseMarker(description(bp)); owner != "ci" || run != "run-1" {
+		t.Fatalf("missing managed label: %q", description(bp))
+	}
+	// The update is merged onto the live definition: undeclared fields are
+	// kept and server-managed ones dropped.
+	if bp["ownership"] == nil || bp["updatedAt"] != nil || bp["relations"] == nil {
+		t.Fatalf("update body not merged onto live definition: %v", bp)
+	}
+	var sent blueprints.Blueprint
+	if err := decode(bp, &sent); err != nil || sent.Schema["properties"].(map[string]any)["tier"].(map[string]any)["type"] != "string" {
+		t.Fatalf("declared change not applied: %+v %v", sent, err)
+	}
+}
+
+func TestPlanIsStableAfterApply(t *testing.T) {
+	res, err := LoadFS(testFS, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	live := []any{
+		map[string]any{"identifier": "team", "title": "Team", "schema": map[string]any{"properties": map[string]any{}}},
+		map[string]any{"identifier": "service", "title": "Service", "icon": "Service", "schema": map[string]any{"properties": map[string]any{}}},
+	}
+	org := &fakeOrg{gets: map[string]any{
+		"/v1/blueprints": map[string]any{"blueprints": live},
+		"/v1/actions":    map[string]any{"actions": []any{}},
+	}}
+	plan, err := New(org, &Options{ManagedBy: "ci", RunID: "run-1"}).Plan(context.Background(), res)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	// Serve what the first apply wrote, then plan again with a new run ID.
+	for _, c := range plan.Changes {
+		switch {
+		case c.Kind == KindBlueprint && c.ID == "service":
+			live[1] = c.body
+		case c.Kind == KindAction:
+			org.gets["/v1/actions"] = map[string]any{"actions": []any{c.body}}
+		default:
+			t.Fatalf("unexpected change %s %s", c.Action, c.ID)
+		}
+	}
+	eng := New(org, &Options{ManagedBy: "ci", RunID: "run-2"})
+	again, err := eng.Plan(context.Background(), res)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if !again.Empty() || again.Unchanged != 3 {
+		t.Fatalf("expected no changes after apply:\n%s", again)
+	}
+	if err := eng.Apply(context.Background(), again); err != nil || len(org.writes) != 0 {
+		t.Fatalf("empty plan wrote %v (err %v)", org.writes, err)
+	}
+}
+
+func TestPlanScorecardsAndPages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"service.json": {Data: []byte(`{"kind":"blueprint","spec":{"identifier":"service","title":"Service","schema":{"properties":{}}}}`)},
+		"scorecards.json": {Data: []byte(`[
+			{"kind":"scorecard","spec":{"blueprint":"service","identifier":"ready","title":"Ready","levels":[{"title":"Gold","color":"gold"}]}},
+			{"kind":"scorecard","spec":{"blueprint":"service","identifier":"new","title":"New","rules":[]}}]`)},
+		"page.json": {Data: []byte(`{"kind":"page","spec":{"identifier":"services","title":"Services","type":"blueprint-entities","blueprint":"service"}}`)},
+	}
+	res, err := LoadFS(fsys, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if res[1].Kind != KindScorecard || res[1].ID != "service/new" || res[len(res)-1].Kind != KindPage {
+		t.Fatalf("unexpected order: %+v", res)
+	}
+	org := &fakeOrg{gets: map[string]any{
+		"/v1/blueprints": map[string]any{"blueprints": []any{
+			map[string]any{"identifier": "service", "title": "Service", "description": " [managed-by:ci run:0 scorecards:ready,stale]", "schema": map[string]any{"properties": map[string]any{}}},
+		}},
+		"/v1/blueprints/service/scorecards": map[string]any{"scorecards": []any{
+			map[string]any{"id": "sc_1", "blueprint": "service", "identifier": "ready", "title": "Ready", "levels": []any{map[string]any{"title": "Silver", "color": "silver"}}, "rules": []any{map[string]any{"identifier": "r"}}},
+			// Applied by an earlier run: pruned.
+			map[string]any{"identifier": "stale", "title": "Stale"},
+			// Created by hand on a managed blueprint: kept.
+			map[string]any{"identifier": "manual", "title": "Manual"},
+		}},
+		"/v1/pages": map[string]any{"pages": []any{
+			map[string]any{"identifier": "old", "title": "Old", "description": " [managed-by:ci run:0]"},
+			map[string]any{"identifier": "home", "title": "Home"},
+		}},
+		"/v1/actions":  map[string]any{"actions": []any{}},
+		"/v1/webhooks": map[string]any{"webhooks": []any{}},
+	}}
+	eng := New(org, &Options{ManagedBy: "ci", RunID: "run-1", Prune: true})
+	plan, err := eng.Plan(context.Background(), res)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if err := eng.Apply(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	want := []string{
+		"PUT /v1/blueprints/service",
+		"POST /v1/blueprints/service/scorecards",
+		"PUT /v1/blueprints/service/scorecards/ready",
+		"POST /v1/pages",
+		"DELETE /v1/blueprints/service/scorecards/stale",
+		"DELETE /v1/pages/old",
+	}
+	if !reflect.DeepEqual(org.writes, want) || plan.Unchanged != 0 {
+		t.Fatalf("writes = %v unchanged=%d\n%s", org.writes, plan.Unchanged, plan)
+	}
+	bp := org.bodies["PUT /v1/blueprints/service"].(map[string]any)
+	if _, _, cards := parseMarker(description(bp)); !reflect.DeepEqual(cards, []string{"new", "ready"}) {
+		t.Fatalf("blueprint label does not record its scorecards: %q", description(bp))
+	}
+	card := org.bodies["PUT /v1/blueprints/service/scorecards/ready"].(map[string]any)
+	if card["blueprint"] != nil || card["id"] != nil || card["rules"] == nil || card["description"] != nil {
+		t.Fatalf("scorecard body: %v", card)
+	}
+	if level := card["levels"].([]any)[0].(map[string]any); level["title"] != "Gold" {
+		t.Fatalf("scorecard levels not updated: %v", card["levels"])
+	}
+	page := org.bodies["POST /v1/pages"].(map[string]any)
+	if owner, _, _ := parseMarker(description(page)); owner != "ci" || page["blueprint"] != "service" {
+		t.Fatalf("page body: %v", page)
+	}
+}
+
+func TestPlanWritesSpecsAsDeclared(t *testing.T) {
+	fsys := fstest.MapFS{"defs.json": {Data: []byte(`[
+		{"kind":"blueprint","spec":{"identifier":"service","ownership":{"type":"Direct"},"aggregationProperties":{"count":{"title":"Count","target":"service","calculationSpec":{"calculationBy":"entities","func":"count"}}}}},
+		{"kind":"action","spec":{"identifier":"scale","title":"Scale","trigger":{"type":"self-service","operation":"DAY-2","blueprintIdentifier":"service","userInputs":{"properties":{"replicas":{"type":"number"}}}}}},
+		{"kind":"webhook","spec":{"identifier":"gh","title":"GitHub","integrationType":"custom"}}]`)},
+	}
+	res, err := LoadFS(fsys, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	org := &fakeOrg{gets: map[string]any{
+		"/v1/actions":  map[string]any{"actions": []any{}},
+		"/v1/webhooks": map[string]any{"webhooks": []any{}},
+	}}
+	eng := New(org, &Options{ManagedBy: "ci", RunID: "run-1"})
+	plan, err := eng.Plan(context.Background(), res)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if err := eng.Apply(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	bp := org.bodies["POST /v1/blueprints"].(map[string]any)
+	if bp["ownership"] == nil || bp["aggregationProperties"] == nil {
+		t.Fatalf("blueprint fields dropped: %v", bp)
+	}
+	if _, ok := bp["title"]; ok {
+		t.Fatalf("undeclared blueprint title written: %v", bp)
+	}
+	trigger := org.bodies["POST /v1/actions"].(map[string]any)["trigger"].(map[string]any)
+	if trigger["operation"] != "DAY-2" || trigger["blueprintIdentifier"] != "service" || trigger["userInputs"] == nil {
+		t.Fatalf("action trigger fields dropped: %v", trigger)
+	}
+	if hook := org.bodies["POST /v1/webhooks"].(map[string]any); hook["integrationType"] != "custom" {
+		t.Fatalf("webhook body: %v", hook)
+	}
+}
@@ -0,0 +1,715 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/automations"
+	"github.com/port-experimental/port-go-sdk/pkg/blueprints"
+	"github.com/port-experimental/port-go-sdk/pkg/datasources"
+	"github.com/port-experimental/port-go-sdk/pkg/organization"
+	"github.com/port-experimental/port-go-sdk/pkg/porter"
+)
+
+// Options configure an Engine.
+type Options struct {
+	// ManagedBy labels every resource written by apply. Prune only deletes
+	// resources carrying the same label. Defaults to "port-go-sdk".
+	ManagedBy string
+	// RunID attributes the changes of one apply. Defaults to a UTC timestamp.
+	// It is only written to resources that change for another reason.
+	RunID string
+	// Prune deletes managed resources that are no longer defined.
+	Prune bool
+	// AllowBreaking lets Apply perform blueprint updates classified as
+	// breaking by blueprints.Diff.
+	AllowBreaking bool
+}
+
+// Engine plans and applies catalog definitions.
+type Engine struct {
+	doer         Doer
+	blueprints   *blueprints.Service
+	actions      *automations.Service
+	datasources  *datasources.Service
+	organization *organization.Service
+	opts         Options
+}
+
+// New returns an engine using doer for API calls.
+func New(doer Doer, opts *Options) *Engine {
+	e := &Engine{
+		doer:         doer,
+		blueprints:   blueprints.New(doer),
+		actions:      automations.New(doer),
+		datasources:  datasources.New(doer),
+		organization: organization.New(doer),
+	}
+	if opts != nil {
+		e.opts = *opts
+	}
+	if e.opts.ManagedBy == "" {
+		e.opts.ManagedBy = "port-go-sdk"
+	}
+	if e.opts.RunID == "" {
+		e.opts.RunID = time.Now().UTC().Format("20060102T150405Z")
+	}
+	return e
+}
+
+// Action is the operation planned for a resource.
+type Action string
+
+// Planned actions.
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var actionSymbols = map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}
+
+// Change is a planned operation on one resource.
+type Change struct {
+	Action Action
+	Kind   Kind
+	ID     string
+	// Details describe what changes for updates.
+	Details []string
+	// Breaking is set for blueprint updates that blueprints.Diff classifies
+	// as breaking.
+	Breaking bool
+
+	desired Resource
+	current any
+	// body is the definition written by Apply: the declared fields for
+	// creates, merged onto the live definition for updates.
+	body map[string]any
+}
+
+// Plan is the set of changes needed to reach the desired definitions.
+type Plan struct {
+	RunID     string
+	Changes   []Change
+	Unchanged int
+}
+
+// Empty reports whether the organization already matches the definitions.
+func (p *Plan) Empty() bool { return len(p.Changes) == 0 }
+
+// String renders a terraform-style summary.
+func (p *Plan) String() string {
+	var b strings.Builder
+	counts := map[Action]int{}
+	for _, c := range p.Changes {
+		counts[c.Action]++
+		fmt.Fprintf(&b, "  %s %s %s\n", actionSymbols[c.Action], c.Kind, c.ID)
+		for _, d := range c.Details {
+			fmt.Fprintf(&b, "      %s\n", d)
+		}
+	}
+	fmt.Fprintf(&b, "Plan: %d to add, %d to change, %d to destroy, %d unchanged.\n",
+		counts[ActionCreate], counts[ActionUpdate], counts[ActionDelete], p.Unchanged)
+	return b.String()
+}
+
+// Plan compares resources with the live organization. Updates are planned
+// as the live definition with the declared fields merged in, which is what
+// Apply sends, so fields a definition leaves out keep their live values.
+func (e *Engine) Plan(ctx context.Context, resources []Resource) (*Plan, error) {
+	kinds := map[Kind]bool{}
+	for _, r := range resources {
+		kinds[r.Kind] = true
+	}
+	current, err := e.fetchCurrent(ctx, kinds, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{RunID: e.opts.RunID}
+	cards := definedScorecards(resources)
+	desired := map[string]bool{}
+	for _, res := range resources {
+		key := string(res.Kind) + "/" + res.ID
+		desired[key] = true
+		cur, exists := current[key]
+		if !exists {
+			if res.Kind == KindIntegration {
+				return nil, fmt.Errorf("catalog: integration %q is not installed", res.ID)
+			}
+			change := Change{Action: ActionCreate, Kind: res.Kind, ID: res.ID, desired: res}
+			change.body = e.labelled(res.Kind, e.document(res), cards[res.ID])
+			plan.Changes = append(plan.Changes, change)
+			continue
+		}
+		change, changed := e.compare(res, cur, cards[res.ID])
+		if !changed {
+			plan.Unchanged++
+			continue
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if e.opts.Prune {
+		keys := make([]string, 0, len(current))
+		for key := range current {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if desired[key] {
+				continue
+			}
+			kind, id, _ := strings.Cut(key, "/")
+			if e.owned(Kind(kind), id, current) {
+				plan.Changes = append(plan.Changes, Change{Action: ActionDelete, Kind: Kind(kind), ID: id, current: current[key]})
+			}
+		}
+	}
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if (a.Action == ActionDelete) != (b.Action == ActionDelete) {
+			return b.Action == ActionDelete
+		}
+		return kindRank[a.Kind] < kindRank[b.Kind]
+	})
+	return plan, nil
+}
+
+// owned reports whether a live resource that is no longer defined may be
+// pruned. Scorecards have no description to carry the managed label, so the
+// label of their blueprint records which of its scorecards were written by
+// apply.
+func (e *Engine) owned(kind Kind, id string, current map[string]any) bool {
+	if kind == KindScorecard {
+		bp, card, _ := strings.Cut(id, "/")
+		owner, _, cards := parseMarker(description(current[string(KindBlueprint)+"/"+bp]))
+		return owner == e.opts.ManagedBy && slices.Contains(cards, card)
+	}
+	owner, _, _ := parseMarker(description(current[string(kind)+"/"+id]))
+	return owner == e.opts.ManagedBy
+}
+
+// fetchCurrent loads the live definitions for the kinds in use, or for every
+// prunable kind when pruning. Keys are "kind/identifier". Definitions are
+// kept as sent by the API, so fields the SDK does not model are preserved
+// when updates are merged onto them.
+func (e *Engine) fetchCurrent(ctx context.Context, kinds map[Kind]bool, resources []Resource) (map[string]any, error) {
+	current := map[string]any{}
+	if kinds[KindBlueprint] || e.opts.Prune {
+		list, err := e.blueprints.ListRaw(ctx)
+		if err == nil {
+			err = addRaw(current, KindBlueprint, "", list)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list blueprints: %w", err)
+		}
+	}
+	for _, bp := range scorecardBlueprints(resources, e.opts.Prune) {
+		var resp struct {
+			Scorecards []json.RawMessage `json:"scorecards"`
+		}
+		err := e.doer.Do(ctx, "GET", scorecardsPath(bp), nil, &resp)
+		if porter.IsNotFound(err) {
+			continue
+		}
+		if err == nil {
+			err = addRaw(current, KindScorecard, bp, resp.Scorecards)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list scorecards of %q: %w", bp, err)
+		}
+	}
+	if kinds[KindAction] || e.opts.Prune {
+		list, err := e.actions.ListActionDefinitionsRaw(ctx, "")
+		if err == nil {
+			err = addRaw(current, KindAction, "", list)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list actions: %w", err)
+		}
+	}
+	if kinds[KindWebhook] || e.opts.Prune {
+		list, err := e.datasources.ListWebhooksRaw(ctx)
+		if err == nil {
+			err = addRaw(current, KindWebhook, "", list)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list webhooks: %w", err)
+		}
+	}
+	if kinds[KindPage] || e.opts.Prune {
+		var resp struct {
+			Pages []json.RawMessage `json:"pages"`
+		}
+		err := e.doer.Do(ctx, "GET", "/v1/pages", nil, &resp)
+		if err == nil {
+			err = addRaw(current, KindPage, "", resp.Pages)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list pages: %w", err)
+		}
+	}
+	for _, res := range resources {
+		if res.Kind != KindIntegration {
+			continue
+		}
+		integ, err := e.datasources.GetIntegration(ctx, res.ID, nil)
+		if porter.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catalog: get integration %q: %w", res.ID, err)
+		}
+		if integ.Identifier != "" {
+			current[string(KindIntegration)+"/"+res.ID] = integ
+		}
+	}
+	return current, nil
+}
+
+// addRaw decodes live definitions into current. Scorecards are keyed by
+// their blueprint, which is recorded in the "blueprint" field.
+func addRaw(current map[string]any, kind Kind, blueprint string, list []json.RawMessage) error {
+	for _, data := range list {
+		m, err := decodeMap(data)
+		if err != nil {
+			return err
+		}
+		id, _ := m["identifier"].(string)
+		if id == "" {
+			continue
+		}
+		if kind == KindScorecard {
+			m["blueprint"] = blueprint
+			id = blueprint + "/" + id
+		}
+		current[string(kind)+"/"+id] = m
+	}
+	return nil
+}
+
+// definedScorecards returns the sorted identifiers of the defined scorecards
+// of each blueprint.
+func definedScorecards(resources []Resource) map[string][]string {
+	out := map[string][]string{}
+	for _, res := range resources {
+		if res.Kind == KindScorecard {
+			bp, id, _ := strings.Cut(res.ID, "/")
+			out[bp] = append(out[bp], id)
+		}
+	}
+	for _, ids := range out {
+		sort.Strings(ids)
+	}
+	return out
+}
+
+// scorecardBlueprints returns the blueprints whose scorecards are compared:
+// those of defined scorecards and, when pruning, every defined blueprint.
+func scorecardBlueprints(resources []Resource, prune bool) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, res := range resources {
+		var bp string
+		switch {
+		case res.Kind == KindScorecard:
+			bp, _, _ = strings.Cut(res.ID, "/")
+		case res.Kind == KindBlueprint && prune:
+			bp = res.ID
+		}
+		if bp != "" && !seen[bp] {
+			seen[bp] = true
+			out = append(out, bp)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// compare reports whether the live resource differs from the desired one.
+// Only fields set in the definition are compared, so server-managed fields
+// and defaults do not produce spurious updates, and the managed label is
+// ignored, so an unchanged resource is not rewritten just to record a new
+// run ID. A blueprint whose label lists other scorecards than cards is
+// rewritten to record them.
+func (e *Engine) compare(res Resource, cur any, cards []string) (Change, bool) {
+	change := Change{Action: ActionUpdate, Kind: res.Kind, ID: res.ID, desired: res, current: cur}
+	if res.Kind == KindIntegration {
+		want := normalize(res.Spec.(IntegrationSpec).Config)
+		if subset(want, normalize(cur.(datasources.Integration).Config)) {
+			return change, false
+		}
+		change.Details = []string{"~ config"}
+		return change, true
+	}
+	want := e.document(res)
+	got := withoutMarker(cur.(map[string]any))
+	_, _, recorded := parseMarker(description(cur))
+	if subset(want, got) && slices.Equal(recorded, cards) {
+		return change, false
+	}
+	merged := merge(got, want).(map[string]any)
+	change.body = e.labelled(res.Kind, merged, cards)
+	if res.Kind == KindBlueprint {
+		var before, after blueprints.Blueprint
+		if decode(got, &before) == nil && decode(merged, &after) == nil {
+			diff := blueprints.Diff(before, after)
+			for _, c := range diff.Changes {
+				change.Details = append(change.Details, c.String())
+			}
+			for _, r := range diff.Renames {
+				change.Details = append(change.Details, "suggestion: "+r.String())
+			}
+			change.Breaking = diff.Severity() == blueprints.SeverityBreaking
+		}
+		if len(change.Details) > 0 {
+			return change, true
+		}
+	}
+	keys := make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !subset(want[k], got[k]) {
+			change.Details = append(change.Details, "~ "+k)
+		}
+	}
+	if !slices.Equal(recorded, cards) {
+		change.Details = append(change.Details, "~ managed scorecards")
+	}
+	return change, true
+}
+
+// document returns the desired definition of res as generic JSON values,
+// without its managed label.
+func (e *Engine) document(res Resource) map[string]any {
+	m, _ := normalize(res.Spec).(map[string]any)
+	if m == nil {
+		m = map[string]any{}
+	}
+	return withoutMarker(m)
+}
+
+// readOnlyFields are server-managed fields of live definitions that are not
+// written back.
+var readOnlyFields = []string{"id", "createdAt", "createdBy", "updatedAt", "updatedBy"}
+
+// readOnlyByKind lists additional server-managed fields per kind.
+var readOnlyByKind = map[Kind][]string{KindWebhook: {"orgId", "url", "webhookKey"}}
+
+// labelled returns the body written for a definition: read-only fields are
+// dropped and the managed label, listing cards for blueprints, is added to
+// the description. Scorecards have no description and are sent without
+// their blueprint, which is part of the request path.
+func (e *Engine) labelled(kind Kind, doc map[string]any, cards []string) map[string]any {
+	out := make(map[string]any, len(doc)+1)
+	for k, v := range doc {
+		out[k] = v
+	}
+	for _, k := range readOnlyFields {
+		delete(out, k)
+	}
+	for _, k := range readOnlyByKind[kind] {
+		delete(out, k)
+	}
+	if kind == KindScorecard {
+		delete(out, "blueprint")
+		return out
+	}
+	desc, _ := out["description"].(string)
+	out["description"] = e.marker(desc, cards)
+	return out
+}
+
+// Apply executes the plan: blueprints first (in relation order), then
+// scorecards, integration configs, webhooks, actions and pages, then
+// deletions in reverse. Each write sends the body computed by Plan.
+// Breaking blueprint updates are refused unless Options.AllowBreaking is set.
+func (e *Engine) Apply(ctx context.Context, plan *Plan) error {
+	if !e.opts.AllowBreaking {
+		for _, c := range plan.Changes {
+			if c.Breaking {
+				return fmt.Errorf("catalog: %s %q has breaking changes; set AllowBreaking to apply", c.Kind, c.ID)
+			}
+		}
+	}
+	var bps []json.RawMessage
+	var deletes []Change
+	for _, c := range plan.Changes {
+		switch {
+		case c.Action == ActionDelete:
+			deletes = append(deletes, c)
+		case c.Kind == KindBlueprint:
+			data, err := json.Marshal(c.body)
+			if err != nil {
+				return fmt.Errorf("catalog: encode blueprint %q: %w", c.ID, err)
+			}
+			bps = append(bps, data)
+		}
+	}
+	if len(bps) > 0 {
+		bpPlan, err := blueprints.NewRawPlan(bps)
+		if err != nil {
+			return fmt.Errorf("catalog: %w", err)
+		}
+		if err := e.blueprints.Apply(ctx, bpPlan); err != nil {
+			return fmt.Errorf("catalog: blueprints: %w", err)
+		}
+	}
+	for _, c := range plan.Changes {
+		if c.Action == ActionDelete || c.Kind == KindBlueprint {
+			continue
+		}
+		if err := e.write(ctx, c); err != nil {
+			return fmt.Errorf("catalog: %s %s %q: %w", c.Action, c.Kind, c.ID, err)
+		}
+	}
+	return e.delete(ctx, deletes)
+}
+
+func (e *Engine) write(ctx context.Context, c Change) error {
+	create := c.Action == ActionCreate
+	switch c.Kind {
+	case KindIntegration:
+		spec := c.desired.Spec.(IntegrationSpec)
+		return e.datasources.UpdateIntegrationConfig(ctx, c.ID, datasources.IntegrationConfigRequest{Config: spec.Config})
+	case KindWebhook:
+		if create {
+			return e.doer.Do(ctx, "POST", "/v1/webhooks", c.body, nil)
+		}
+		return e.doer.Do(ctx, "PATCH", "/v1/webhooks/"+url.PathEscape(c.ID), c.body, nil)
+	case KindAction:
+		if create {
+			return e.doer.Do(ctx, "POST", "/v1/actions", c.body, nil)
+		}
+		return e.doer.Do(ctx, "PUT", "/v1/actions/"+url.PathEscape(c.ID), c.body, nil)
+	case KindScorecard:
+		bp, id, _ := strings.Cut(c.ID, "/")
+		if create {
+			return e.doer.Do(ctx, "POST", scorecardsPath(bp), c.body, nil)
+		}
+		return e.doer.Do(ctx, "PUT", scorecardsPath(bp)+"/"+url.PathEscape(id), c.body, nil)
+	case KindPage:
+		if create {
+			return e.doer.Do(ctx, "POST", "/v1/pages", c.body, nil)
+		}
+		return e.doer.Do(ctx, "PATCH", "/v1/pages/"+url.PathEscape(c.ID), c.body, nil)
+	default:
+		return fmt.Errorf("unsupported kind %q", c.Kind)
+	}
+}
+
+// delete removes scorecards, actions, webhooks and pages, then blueprints
+// dependents first. Blueprint entities are not deleted; Port rejects deleting
+// a blueprint that still has entities.
+func (e *Engine) delete(ctx context.Context, deletes []Change) error {
+	var bps []blueprints.Blueprint
+	for _, c := range deletes {
+		var err error
+		switch c.Kind {
+		case KindScorecard:
+			bp, id, _ := strings.Cut(c.ID, "/")
+			err = e.doer.Do(ctx, "DELETE", scorecardsPath(bp)+"/"+url.PathEscape(id), nil, nil)
+		case KindAction:
+			err = e.actions.DeleteAction(ctx, c.ID)
+		case KindWebhook:
+			err = e.datasources.DeleteWebhook(ctx, c.ID)
+		case KindPage:
+			err = e.doer.Do(ctx, "DELETE", "/v1/pages/"+url.PathEscape(c.ID), nil, nil)
+		case KindBlueprint:
+			var bp blueprints.Blueprint
+			if err = decode(c.current, &bp); err == nil {
+				bps = append(bps, bp)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("catalog: delete %s %q: %w", c.Kind, c.ID, err)
+		}
+	}
+	if len(bps) == 0 {
+		return nil
+	}
+	bpPlan, err := blueprints.NewPlan(bps)
+	if err != nil {
+		return fmt.Errorf("catalog: %w", err)
+	}
+	for _, id := range bpPlan.TeardownOrder() {
+		if err := e.blueprints.Delete(ctx, id); err != nil {
+			return fmt.Errorf("catalog: delete blueprint %q: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func scorecardsPath(blueprint string) string {
+	return fmt.Sprintf("/v1/blueprints/%s/scorecards", url.PathEscape(blueprint))
+}
+
+// The managed label is stored as a suffix of the resource description, the
+// only free-form field shared by blueprints, actions, webhooks and pages. It
+// is visible in Port's UI, so compare ignores it and an otherwise unchanged
+// resource keeps the run ID of the apply that last changed it. A blueprint's
+// label also lists the scorecards applied to it, which have no description
+// of their own: " [managed-by:ci run:R scorecards:a,b]".
+const (
+	markerPrefix     = " [managed-by:"
+	markerScorecards = " scorecards:"
+)
+
+func (e *Engine) marker(desc string, cards []string) string {
+	var list string
+	if len(cards) > 0 {
+		list = markerScorecards + strings.Join(cards, ",")
+	}
+	return fmt.Sprintf("%s%s%s run:%s%s]", cleanDescription(desc), markerPrefix, e.opts.ManagedBy, e.opts.RunID, list)
+}
+
+func cleanDescription(desc string) string {
+	if i := strings.Index(desc, markerPrefix); i >= 0 {
+		return desc[:i]
+	}
+	return desc
+}
+
+// parseMarker returns the owner, run ID and scorecards recorded in a
+// description.
+func parseMarker(desc string) (owner, runID string, cards []string) {
+	i := strings.Index(desc, markerPrefix)
+	if i < 0 || !strings.HasSuffix(desc, "]") {
+		return "", "", nil
+	}
+	fields := strings.TrimSuffix(desc[i+len(markerPrefix):], "]")
+	owner, runID, _ = strings.Cut(fields, " run:")
+	runID, list, ok := strings.Cut(runID, markerScorecards)
+	if ok && list != "" {
+		cards = strings.Split(list, ",")
+	}
+	return owner, runID, cards
+}
+
+// withoutMarker returns a shallow copy of m with the managed label removed
+// from its description.
+func withoutMarker(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	desc, ok := out["description"].(string)
+	if !ok {
+		return out
+	}
+	if desc = cleanDescription(desc); desc == "" {
+		delete(out, "description")
+	} else {
+		out["description"] = desc
+	}
+	return out
+}
+
+func description(v any) string {
+	m, _ := v.(map[string]any)
+	desc, _ := m["description"].(string)
+	return desc
+}
+
+// subset reports whether every value set in want matches got. Nil values in
+// want are treated as unset.
+func subset(want, got any) bool {
+	switch w := want.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return len(w) == 0 && got == nil
+		}
+		for k, v := range w {
+			if !subset(v, g[k]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return len(w) == 0 && got == nil
+		}
+		for i := range w {
+			if !subset(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(want, got)
+	}
+}
+
+// merge returns got with every value set in want applied, mirroring subset:
+// maps are merged key by key, nil values in want are skipped, arrays of the
+// same length are merged element by element and anything else is replaced.
+// got is not modified. subset(want, merge(got, want)) always holds, so once
+// an update is applied the next plan finds no change.
+func merge(got, want any) any {
+	switch w := want.(type) {
+	case nil:
+		return got
+	case map[string]any:
+		g, _ := got.(map[string]any)
+		out := make(map[string]any, len(g)+len(w))
+		for k, v := range g {
+			out[k] = v
+		}
+		for k, v := range w {
+			if v != nil {
+				out[k] = merge(g[k], v)
+			}
+		}
+		return out
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			g = make([]any, len(w))
+		}
+		out := make([]any, len(w))
+		for i := range w {
+			out[i] = merge(g[i], w[i])
+		}
+		return out
+	default:
+		return want
+	}
+}
+
+// normalize converts typed values into generic JSON values. Numbers are
+// kept as json.Number so they compare equal to live definitions.
+func normalize(v any) any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out any
+	if err := unmarshalJSON(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// decode converts generic JSON values into a typed value.
+func decode(v any, out any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
@@ -0,0 +1,384 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Export reads the live definitions of the organization as resources that
+// can be saved with the file format read by LoadFS or planned against
+// another organization. With no kinds, blueprints, scorecards, actions
+// (including automations), webhooks and pages are exported. Definitions are
+// kept as sent by the API, so fields the SDK does not model are promoted too.
+// Managed labels and server-managed fields are removed and webhook secrets
+// are replaced by SecretPlaceholder values.
+func (e *Engine) Export(ctx context.Context, kinds ...Kind) ([]Resource, error) {
+	want := map[Kind]bool{}
+	for _, k := range kinds {
+		if _, ok := kindRank[k]; !ok || k == KindIntegration {
+			return nil, fmt.Errorf("catalog: kind %q cannot be exported", k)
+		}
+		want[k] = true
+	}
+	all := len(kinds) == 0
+	var out []Resource
+	if all || want[KindBlueprint] || want[KindScorecard] {
+		list, err := e.blueprints.ListRaw(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list blueprints: %w", err)
+		}
+		bps, err := exportRaw(KindBlueprint, list)
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list blueprints: %w", err)
+		}
+		for _, bp := range bps {
+			if all || want[KindBlueprint] {
+				out = append(out, bp)
+			}
+			if all || want[KindScorecard] {
+				cards, err := e.exportScorecards(ctx, bp.ID)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, cards...)
+			}
+		}
+	}
+	if all || want[KindAction] {
+		list, err := e.actions.ListActionDefinitionsRaw(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list actions: %w", err)
+		}
+		actions, err := exportRaw(KindAction, list)
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list actions: %w", err)
+		}
+		out = append(out, actions...)
+	}
+	if all || want[KindWebhook] {
+		list, err := e.datasources.ListWebhooksRaw(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list webhooks: %w", err)
+		}
+		hooks, err := exportRaw(KindWebhook, list)
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list webhooks: %w", err)
+		}
+		for _, h := range hooks {
+			spec := h.Spec.(map[string]any)
+			if sec, ok := spec["security"].(map[string]any); ok {
+				sec = copyMap(sec)
+				sec["secret"] = SecretPlaceholder("webhook/" + h.ID)
+				spec["security"] = sec
+			}
+			out = append(out, h)
+		}
+	}
+	if all || want[KindPage] {
+		var resp struct {
+			Pages []json.RawMessage `json:"pages"`
+		}
+		err := e.doer.Do(ctx, "GET", "/v1/pages", nil, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list pages: %w", err)
+		}
+		pages, err := exportRaw(KindPage, resp.Pages)
+		if err != nil {
+			return nil, fmt.Errorf("catalog: list pages: %w", err)
+		}
+		out = append(out, pages...)
+	}
+	sortResources(out)
+	return out, nil
+}
+
+// exportRaw decodes live definitions of one kind into resources, sorted by
+// identifier.
+func exportRaw(kind Kind, list []json.RawMessage) ([]Resource, error) {
+	current := map[string]any{}
+	if err := addRaw(current, kind, "", list); err != nil {
+		return nil, err
+	}
+	out := make([]Resource, 0, len(current))
+	for key, def := range current {
+		_, id, _ := strings.Cut(key, "/")
+		out = append(out, Resource{Kind: kind, ID: id, Spec: exported(kind, def.(map[string]any))})
+	}
+	sortResources(out)
+	return out, nil
+}
+
+// exportScorecards returns the scorecards of a blueprint.
+func (e *Engine) exportScorecards(ctx context.Context, blueprint string) ([]Resource, error) {
+	var resp struct {
+		Scorecards []json.RawMessage `json:"scorecards"`
+	}
+	current := map[string]any{}
+	err := e.doer.Do(ctx, "GET", scorecardsPath(blueprint), nil, &resp)
+	if err == nil {
+		err = addRaw(current, KindScorecard, blueprint, resp.Scorecards)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("catalog: list scorecards of %q: %w", blueprint, err)
+	}
+	out := make([]Resource, 0, len(current))
+	for key, card := range current {
+		_, id, _ := strings.Cut(key, "/")
+		out = append(out, Resource{Kind: KindScorecard, ID: id, Spec: exported(KindScorecard, card.(map[string]any))})
+	}
+	return out, nil
+}
+
+// exported returns a raw definition without its managed label and
+// server-managed fields.
+func exported(kind Kind, m map[string]any) map[string]any {
+	out := withoutMarker(m)
+	for _, k := range readOnlyFields {
+		delete(out, k)
+	}
+	for _, k := range readOnlyByKind[kind] {
+		delete(out, k)
+	}
+	return out
+}
+
+// SecretPlaceholder returns the placeholder Export writes instead of a
+// secret value. Promote resolves placeholders from PromoteOptions.Secrets.
+func SecretPlaceholder(name string) string {
+	return "{{secret:" + name + "}}"
+}
+
+var placeholderRE = regexp.MustCompile(`^\{\{secret:(.+)\}\}$`)
+
+// RemapRule rewrites identifiers while promoting, for example
+// {Kind: KindBlueprint, Match: regexp.MustCompile(`^staging_(.*)$`), Replace: "$1"}.
+// An empty Kind applies to every kind. Blueprint rules also rewrite
+// references: relation and aggregation targets, scorecard blueprints, webhook
+// mapping blueprints, and every "blueprint" and "blueprintIdentifier" field
+// of actions and pages, which covers automation and self-service triggers,
+// invocation methods, entity user inputs and page widgets. Action rules also
+// rewrite the actions automations react to, and page rules page parents.
+// Rules for scorecards match the scorecard identifier without its blueprint.
+type RemapRule struct {
+	Kind    Kind
+	Match   *regexp.Regexp
+	Replace string
+}
+
+// PromoteOptions configure Promote.
+type PromoteOptions struct {
+	// Kinds limits the exported kinds. Defaults to every kind except
+	// integrations.
+	Kinds []Kind
+	// Select filters resources by their source identifiers. Nil selects all.
+	Select func(Resource) bool
+	// Remap rewrites identifiers; the first matching rule wins.
+	Remap []RemapRule
+	// Secrets resolves secret placeholders by name. Unresolved webhook
+	// secrets are omitted so the target keeps its current secret.
+	Secrets map[string]string
+}
+
+// Promotion is the result of Promote.
+type Promotion struct {
+	// Resources are the remapped definitions planned against the target.
+	Resources []Resource
+	// Plan is the target engine's plan; apply it with target.Apply.
+	Plan *Plan
+	// MissingSecrets lists organization secrets present in the source but
+	// not in the target. Their values cannot be read and must be created by hand.
+	MissingSecrets []string
+	// UnresolvedSecrets lists placeholders without a value in PromoteOptions.Secrets.
+	UnresolvedSecrets []string
+}
+
+// Promote exports resources from source, applies the selection and remap
+// rules and plans them against target. Nothing is written; review
+// Promotion.Plan and pass it to target.Apply. The target engine's Options
+// (managed label, prune, breaking changes) apply as usual.
+func Promote(ctx context.Context, source, target *Engine, opts *PromoteOptions) (*Promotion, error) {
+	if opts == nil {
+		opts = &PromoteOptions{}
+	}
+	exported, err := source.Export(ctx, opts.Kinds...)
+	if err != nil {
+		return nil, err
+	}
+	promo := &Promotion{}
+	for _, res := range exported {
+		if opts.Select != nil && !opts.Select(res) {
+			continue
+		}
+		res, unresolved := resolveSecrets(res, opts.Secrets)
+		promo.UnresolvedSecrets = append(promo.UnresolvedSecrets, unresolved...)
+		promo.Resources = append(promo.Resources, remapResource(res, opts.Remap))
+	}
+	sortResources(promo.Resources)
+	if promo.MissingSecrets, err = missingSecrets(ctx, source, target); err != nil {
+		return nil, err
+	}
+	if promo.Plan, err = target.Plan(ctx, promo.Resources); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// DiffOrgs plans the source organization's resources against the target
+// without remapping, reporting what promoting everything would change.
+func DiffOrgs(ctx context.Context, source, target *Engine, kinds ...Kind) (*Plan, error) {
+	promo, err := Promote(ctx, source, target, &PromoteOptions{Kinds: kinds})
+	if err != nil {
+		return nil, err
+	}
+	return promo.Plan, nil
+}
+
+func missingSecrets(ctx context.Context, source, target *Engine) ([]string, error) {
+	src, err := source.organization.ListSecrets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: list source secrets: %w", err)
+	}
+	dst, err := target.organization.ListSecrets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: list target secrets: %w", err)
+	}
+	have := map[string]bool{}
+	for _, s := range dst.Secrets {
+		have[s.SecretName] = true
+	}
+	var missing []string
+	for _, s := range src.Secrets {
+		if !have[s.SecretName] {
+			missing = append(missing, s.SecretName)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+func resolveSecrets(res Resource, secrets map[string]string) (Resource, []string) {
+	if res.Kind != KindWebhook {
+		return res, nil
+	}
+	hook := res.Spec.(map[string]any)
+	sec, ok := hook["security"].(map[string]any)
+	if !ok {
+		return res, nil
+	}
+	secret, _ := sec["secret"].(string)
+	m := placeholderRE.FindStringSubmatch(secret)
+	if m == nil {
+		return res, nil
+	}
+	hook, sec = copyMap(hook), copyMap(sec)
+	var unresolved []string
+	if v, ok := secrets[m[1]]; ok {
+		sec["secret"] = v
+	} else {
+		delete(sec, "secret")
+		unresolved = append(unresolved, m[1])
+	}
+	hook["security"] = sec
+	res.Spec = hook
+	return res, unresolved
+}
+
+func remapID(rules []RemapRule, kind Kind, id string) string {
+	for _, r := range rules {
+		if r.Match == nil || (r.Kind != "" && r.Kind != kind) || !r.Match.MatchString(id) {
+			continue
+		}
+		return r.Match.ReplaceAllString(id, r.Replace)
+	}
+	return id
+}
+
+func remapResource(res Resource, rules []RemapRule) Resource {
+	if len(rules) == 0 {
+		return res
+	}
+	bpID := func(id string) string { return remapID(rules, KindBlueprint, id) }
+	bpRef := func(id string) string { return remapBlueprintRef(id, bpID) }
+	spec := copyMap(res.Spec.(map[string]any))
+	if res.Kind == KindScorecard {
+		bp, _ := spec["blueprint"].(string)
+		id, _ := spec["identifier"].(string)
+		bp, id = bpRef(bp), remapID(rules, KindScorecard, id)
+		spec["blueprint"], spec["identifier"] = bp, id
+		res.ID, res.Spec = bp+"/"+id, spec
+		return res
+	}
+	res.ID = remapID(rules, res.Kind, res.ID)
+	spec["identifier"] = res.ID
+	switch res.Kind {
+	case KindBlueprint:
+		for _, field := range []string{"relations", "aggregationProperties"} {
+			if props, ok := spec[field].(map[string]any); ok {
+				spec[field] = remapRefs(props, map[string]func(string) string{"target": bpRef})
+			}
+		}
+	case KindAction:
+		spec = remapRefs(spec, map[string]func(string) string{
+			"blueprint":           bpRef,
+			"blueprintIdentifier": bpRef,
+			"actionIdentifier":    func(id string) string { return remapID(rules, KindAction, id) },
+		}).(map[string]any)
+	case KindWebhook:
+		if mappings, ok := spec["mappings"].([]any); ok {
+			spec["mappings"] = remapRefs(mappings, map[string]func(string) string{"blueprint": bpRef})
+		}
+	case KindPage:
+		spec = remapRefs(spec, map[string]func(string) string{"blueprint": bpRef}).(map[string]any)
+		if parent, ok := spec["parent"].(string); ok {
+			spec["parent"] = remapID(rules, KindPage, parent)
+		}
+	}
+	res.Spec = spec
+	return res
+}
+
+// remapRefs returns a copy of v with every string stored under a key of refs
+// rewritten by that key's function, at any depth.
+func remapRefs(v any, refs map[string]func(string) string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			if id, ok := val.(string); ok && refs[k] != nil {
+				out[k] = refs[k](id)
+				continue
+			}
+			out[k] = remapRefs(val, refs)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = remapRefs(val, refs)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func copyMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// remapBlueprintRef rewrites a blueprint identifier that may be written as a
+// quoted JQ string literal, as in webhook mappings.
+func remapBlueprintRef(bp string, bpID func(string) string) string {
+	if inner, quoted := strings.CutPrefix(bp, `"`); quoted && strings.HasSuffix(inner, `"`) {
+		return `"` + bpID(strings.TrimSuffix(inner, `"`)) + `"`
+	}
+	return bpID(bp)
+}
@@ -0,0 +1,159 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPromoteRemapsAndResolvesSecrets(t *testing.T) {
+	source := &fakeOrg{gets: map[string]any{
+		"/v1/blueprints": map[string]any{"blueprints": []any{
+			map[string]any{"identifier": "stg_service", "title": "Service", "description": "svc [managed-by:ci run:1]", "relations": map[string]any{"team": map[string]any{"title": "Team", "target": "stg_team"}},
+				"aggregationProperties": map[string]any{"members": map[string]any{"title": "Members", "target": "stg_team", "calculationSpec": map[string]any{"func": "count"}}}},
+			map[string]any{"identifier": "stg_team", "title": "Team"},
+			map[string]any{"identifier": "scratch", "title": "Scratch"},
+		}},
+		"/v1/actions": map[string]any{"actions": []any{
+			map[string]any{"identifier": "notify", "trigger": map[string]any{"type": "automation", "event": map[string]any{"type": "ENTITY_CREATED", "blueprintIdentifier": "stg_service"}}},
+			map[string]any{"identifier": "scale", "trigger": map[string]any{"type": "self-service", "operation": "DAY-2", "blueprintIdentifier": "stg_service",
+				"userInputs": map[string]any{"properties": map[string]any{"team": map[string]any{"type": "string", "format": "entity", "blueprint": "stg_team"}}}},
+				"invocationMethod": map[string]any{"type": "UPSERT_ENTITY", "blueprintIdentifier": "stg_service", "mapping": map[string]any{"identifier": "{{ .entity.identifier }}"}}},
+		}},
+		"/v1/webhooks": map[string]any{"webhooks": []any{
+			map[string]any{"identifier": "gh", "title": "GitHub", "enabled": true, "mappings": []any{map[string]any{"blueprint": `"stg_service"`}}, "security": map[string]any{"secret": "s3cr3t"}},
+		}},
+		"/v1/organization/secrets": map[string]any{"secrets": []any{map[string]any{"secretName": "SLACK"}, map[string]any{"secretName": "GH"}}},
+	}}
+	target := &fakeOrg{gets: map[string]any{
+		"/v1/blueprints":           map[string]any{"blueprints": []any{map[string]any{"identifier": "team", "title": "Team"}}},
+		"/v1/actions":              map[string]any{"actions": []any{}},
+		"/v1/webhooks":             map[string]any{"webhooks": []any{}},
+		"/v1/organization/secrets": map[string]any{"secrets": []any{map[string]any{"secretName": "GH"}}},
+	}}
+	promo, err := Promote(context.Background(), New(source, nil), New(target, nil), &PromoteOptions{
+		Select:  func(r Resource) bool { return r.ID != "scratch" },
+		Remap:   []RemapRule{{Kind: KindBlueprint, Match: regexp.MustCompile(`^stg_(.*)$`), Replace: "$1"}},
+		Secrets: map[string]string{"webhook/gh": "prod-secret"},
+	})
+	if err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	var ids []string
+	for _, r := range promo.Resources {
+		ids = append(ids, r.ID)
+	}
+	if want := []string{"service", "team", "gh", "notify", "scale"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("resources = %v", ids)
+	}
+	bp := promo.Resources[0].Spec.(map[string]any)
+	if lookup(bp, "relations", "team", "target") != "team" || lookup(bp, "aggregationProperties", "members", "target") != "team" || bp["description"] != "svc" {
+		t.Fatalf("blueprint not remapped: %v", bp)
+	}
+	hook := promo.Resources[2].Spec.(map[string]any)
+	if hook["mappings"].([]any)[0].(map[string]any)["blueprint"] != `"service"` || lookup(hook, "security", "secret") != "prod-secret" {
+		t.Fatalf("webhook not remapped: %v", hook)
+	}
+	if notify := promo.Resources[3].Spec.(map[string]any); lookup(notify, "trigger", "event", "blueprintIdentifier") != "service" {
+		t.Fatalf("automation trigger not remapped: %v", notify["trigger"])
+	}
+	scale := promo.Resources[4].Spec.(map[string]any)
+	if lookup(scale, "trigger", "blueprintIdentifier") != "service" || lookup(scale, "trigger", "operation") != "DAY-2" ||
+		lookup(scale, "trigger", "userInputs", "properties", "team", "blueprint") != "team" ||
+		lookup(scale, "invocationMethod", "blueprintIdentifier") != "service" {
+		t.Fatalf("self-service action not remapped: %v", scale)
+	}
+	if !reflect.DeepEqual(promo.MissingSecrets, []string{"SLACK"}) || len(promo.UnresolvedSecrets) != 0 {
+		t.Fatalf("secrets: missing=%v unresolved=%v", promo.MissingSecrets, promo.UnresolvedSecrets)
+	}
+	if promo.Plan.Unchanged != 1 || len(promo.Plan.Changes) != 4 {
+		t.Fatalf("unexpected plan:\n%s", promo.Plan)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	org := &fakeOrg{gets: map[string]any{
+		"/v1/webhooks": map[string]any{"webhooks": []any{
+			map[string]any{"identifier": "gh", "title": "GitHub", "enabled": true, "security": map[string]any{"secret": "s3cr3t"}},
+		}},
+	}}
+	res, err := New(org, nil).Export(context.Background(), KindWebhook)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	loaded, err := LoadFS(fstest.MapFS{"export.json": {Data: data}}, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	hook := loaded[0].Spec.(map[string]any)
+	if hook["identifier"] != "gh" || hook["security"].(map[string]any)["secret"] != SecretPlaceholder("webhook/gh") {
+		t.Fatalf("round trip lost data: %+v", hook)
+	}
+	if _, err := New(org, nil).Export(context.Background(), KindIntegration); err == nil {
+		t.Fatalf("expected unsupported kind error")
+	}
+}
+
+func TestPromoteScorecardsAndPages(t *testing.T) {
+	source := &fakeOrg{gets: map[string]any{
+		"/v1/blueprints": map[string]any{"blueprints": []any{map[string]any{"identifier": "stg_service", "title": "Service"}}},
+		"/v1/blueprints/stg_service/scorecards": map[string]any{"scorecards": []any{
+			map[string]any{"id": "sc_1", "identifier": "stg_ready", "title": "Ready", "blueprint": "stg_service", "createdAt": "2024-01-01", "levels": []any{map[string]any{"title": "Gold"}}},
+		}},
+		"/v1/pages": map[string]any{"pages": []any{
+			map[string]any{"identifier": "stg_services", "title": "Services", "parent": "stg_folder", "description": "all [managed-by:ci run:1]", "blueprint": "stg_service",
+				"widgets": []any{map[string]any{"type": "table-entities-explorer", "dataset": map[string]any{"blueprint": "stg_service"}}}},
+		}},
+	}}
+	target := &fakeOrg{gets: map[string]any{}}
+	promo, err := Promote(context.Background(), New(source, nil), New(target, nil), &PromoteOptions{
+		Kinds: []Kind{KindScorecard, KindPage},
+		Remap: []RemapRule{{Match: regexp.MustCompile(`^stg_(.*)$`), Replace: "$1"}},
+	})
+	if err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	var ids []string
+	for _, r := range promo.Resources {
+		ids = append(ids, string(r.Kind)+"/"+r.ID)
+	}
+	if want := []string{"scorecard/service/ready", "page/services"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("resources = %v", ids)
+	}
+	card := promo.Resources[0].Spec.(map[string]any)
+	if card["blueprint"] != "service" || card["identifier"] != "ready" || card["id"] != nil || card["createdAt"] != nil || card["levels"] == nil {
+		t.Fatalf("scorecard not remapped: %v", card)
+	}
+	page := promo.Resources[1].Spec.(map[string]any)
+	widget := page["widgets"].([]any)[0].(map[string]any)
+	if page["identifier"] != "services" || page["parent"] != "folder" || page["blueprint"] != "service" ||
+		widget["dataset"].(map[string]any)["blueprint"] != "service" || page["description"] != "all" {
+		t.Fatalf("page not remapped: %v", page)
+	}
+	// Remapping copies the payload instead of modifying the export.
+	exported, _ := New(source, nil).Export(context.Background(), KindPage)
+	remapResource(exported[0], []RemapRule{{Match: regexp.MustCompile(`^stg_(.*)$`), Replace: "$1"}})
+	if w := exported[0].Spec.(map[string]any)["widgets"].([]any)[0].(map[string]any); w["dataset"].(map[string]any)["blueprint"] != "stg_service" {
+		t.Fatalf("export modified by remap: %v", exported[0].Spec)
+	}
+	if len(promo.Plan.Changes) != 2 || promo.Plan.Changes[0].Action != ActionCreate {
+		t.Fatalf("unexpected plan:\n%s", promo.Plan)
+	}
+}
+
+// lookup returns the value at keys in nested maps, or nil.
+func lookup(m map[string]any, keys ...string) any {
+	var v any = m
+	for _, k := range keys {
+		next, _ := v.(map[string]any)
+		v = next[k]
+	}
+	return v
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"github.com/port-experimental/port-go-sdk/pkg/auth"
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+)
+
+// WithTokenSource authenticates requests with ts instead of the credentials
+// in the config, for example tokens from Vault or a workload identity. The
+// config then needs no credentials. Sources implementing
+// auth.InvalidatingTokenSource are asked for a new token after a 401, and
+// those implementing auth.TokenInfoProvider back Client.TokenInfo. The
+// client does not close ts.
+func WithTokenSource(ts auth.TokenSource) Option {
+	return func(c *Client) { c.tokenSource = ts }
+}
+
+// WithTokenRefresh configures the client credentials token source, for
+// example to refresh tokens in the background before they expire:
+//
+//	client.WithTokenRefresh(auth.ClientCredentialsOptions{RefreshFraction: 0.75})
+//
+// It has no effect with a personal API token or WithTokenSource.
+func WithTokenRefresh(opts auth.ClientCredentialsOptions) Option {
+	return func(c *Client) { c.credsOpts = opts }
+}
+
+// WithTokenCache shares client credentials tokens between processes through
+// an auth.CachingTokenSource, so CLI invocations and short-lived jobs reuse a
+// valid token instead of each exchanging credentials:
+//
+//	client.WithTokenCache(auth.TokenCacheOptions{EncryptionKey: key})
+//
+// It takes precedence over WithTokenRefresh and has no effect with a
+// personal API token or WithTokenSource.
+func WithTokenCache(opts auth.TokenCacheOptions) Option {
+	return func(c *Client) { c.tokenCache = &opts }
+}
+
+// TokenInfo reports on the current access token, for example its expiry for
+// health checks. ok is false when the token source does not provide it.
+func (c *Client) TokenInfo() (info auth.TokenInfo, ok bool) {
+	p, ok := c.tokenSource.(auth.TokenInfoProvider)
+	if !ok {
+		return auth.TokenInfo{}, false
+	}
+	return p.TokenInfo(), true
+}
+
+// initTokenSource builds the token source from cfg unless WithTokenSource
+// provided one.
+func (c *Client) initTokenSource(cfg config.Config) error {
+	if c.tokenSource != nil {
+		return nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.APIToken != "" {
+		c.tokenSource = auth.NewTokenSource(cfg, c.authDoer())
+		return nil
+	}
+	if c.tokenCache != nil {
+		src, err := auth.NewCachingTokenSource(cfg, c.authDoer(), *c.tokenCache)
+		if err != nil {
+			return err
+		}
+		c.tokenSource = src
+		return nil
+	}
+	src := auth.NewClientCredentialsSource(cfg, c.authDoer(), c.credsOpts)
+	c.tokenSource = src
+	c.closeTokenSource = src.Close
+	return nil
+}
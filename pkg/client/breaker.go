@@ -0,0 +1,40 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/port-experimental/port-go-sdk/pkg/breaker"
+)
+
+// ErrCircuitOpen is matched (with errors.Is) by errors returned for requests
+// rejected by an open circuit breaker. The concrete error is a
+// *breaker.OpenError naming the route group.
+var ErrCircuitOpen = breaker.ErrCircuitOpen
+
+// WithCircuitBreaker enables a circuit breaker in front of retries. Each route
+// group (see breaker.DefaultGroup) opens after opts.ConsecutiveFailures
+// failed calls or when opts.FailureRate is reached, and rejects calls with
+// ErrCircuitOpen until opts.OpenTimeout has passed and a trial call succeeds.
+func WithCircuitBreaker(opts breaker.Options) Option {
+	return func(c *Client) { c.breaker = breaker.New(opts) }
+}
+
+// CircuitStates returns the state of every circuit that has seen a request.
+// It returns nil when no circuit breaker is configured.
+func (c *Client) CircuitStates() map[string]breaker.State {
+	if c.breaker == nil {
+		return nil
+	}
+	return c.breaker.States()
+}
+
+// CircuitBreakerMiddleware runs each call through b. Installed outside retry,
+// a call counts once however many attempts it takes, and rejected calls fail
+// without waiting for backoff.
+func CircuitBreakerMiddleware(b *breaker.Breaker) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return b.Do(req, next)
+		}
+	}
+}
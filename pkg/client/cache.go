@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/port-experimental/port-go-sdk/pkg/cache"
+)
+
+// WithCache enables an in-memory read cache for entity and blueprint lookups
+// (Entities().Get, Blueprints().Get and Blueprints().List). Entries expire
+// after opts.TTL and the least recently used entries are evicted beyond
+// opts.MaxEntries. Writes issued through this client (create, upsert, update,
+// delete, link/unlink, blueprint changes) invalidate the affected keys.
+// Changes made by other clients are only observed once entries expire.
+func WithCache(opts cache.Options) Option {
+	return func(c *Client) { c.cache = cache.New[json.RawMessage](opts) }
+}
+
+// CacheStats returns hit/miss counters for the read cache. It returns the
+// zero value when caching is disabled.
+func (c *Client) CacheStats() cache.Stats {
+	if c.cache == nil {
+		return cache.Stats{}
+	}
+	return c.cache.Stats()
+}
+
+// PurgeCache drops every cached response.
+func (c *Client) PurgeCache() {
+	if c.cache != nil {
+		c.cache.Purge()
+	}
+}
+
+// doCached serves cacheable reads from the cache and invalidates cached keys
+// affected by writes. It reports false when the request bypasses the cache.
+func (c *Client) doCached(ctx context.Context, method, path string, body, out any) (bool, error) {
+	route := path
+	if i := strings.IndexByte(route, '?'); i >= 0 {
+		route = route[:i]
+	}
+	segs := strings.Split(strings.Trim(route, "/"), "/")
+	if method == http.MethodGet {
+		if body != nil || !cacheableRead(segs) || requestOptionsFrom(ctx) != nil {
+			return false, nil
+		}
+		raw, err := c.cache.Get(ctx, path, func(ctx context.Context) (json.RawMessage, error) {
+			var raw json.RawMessage
+			err := c.do(ctx, method, path, nil, &raw)
+			return raw, err
+		})
+		if err != nil || out == nil {
+			return true, err
+		}
+		return true, json.Unmarshal(raw, out)
+	}
+	keys, prefixes := invalidatedKeys(segs)
+	for _, key := range keys {
+		c.cache.Invalidate(key)
+	}
+	for _, prefix := range prefixes {
+		c.cache.InvalidatePrefix(prefix)
+	}
+	return false, nil
+}
+
+// cacheableRead matches /v1/blueprints, /v1/blueprints/{bp} and
+// /v1/blueprints/{bp}/entities/{id}.
+func cacheableRead(segs []string) bool {
+	if len(segs) < 2 || segs[0] != "v1" || segs[1] != "blueprints" {
+		return false
+	}
+	switch len(segs) {
+	case 2, 3:
+		return true
+	case 5:
+		return segs[3] == "entities"
+	}
+	return false
+}
+
+// invalidatedKeys maps a write route to the exact cache keys and key
+// prefixes it may make stale. Read-only POST routes (search) return nothing.
+func invalidatedKeys(segs []string) (keys, prefixes []string) {
+	if len(segs) < 2 || segs[0] != "v1" || segs[1] != "blueprints" {
+		return nil, nil
+	}
+	if len(segs) == 2 {
+		return []string{"/v1/blueprints"}, nil
+	}
+	bp := "/v1/blueprints/" + segs[2]
+	entitiesPrefix := bp + "/entities/"
+	switch {
+	case len(segs) == 3:
+		// Blueprint update or delete: the blueprint, the list and its entities.
+		return []string{bp, "/v1/blueprints"}, []string{entitiesPrefix}
+	case len(segs) >= 5 && segs[3] == "entities" && segs[4] == "search":
+		return nil, nil
+	case len(segs) >= 5 && segs[3] == "entities" && segs[4] != "bulk":
+		// Entity write, including relation link/unlink.
+		return []string{entitiesPrefix + segs[4]}, nil
+	case segs[3] == "entities" || segs[3] == "bulk":
+		// Create/upsert/bulk writes carry identifiers in the body.
+		return nil, []string{entitiesPrefix}
+	default:
+		// Property/relation renames and permissions change the blueprint and
+		// may change how its entities are returned.
+		return []string{bp, "/v1/blueprints"}, []string{entitiesPrefix}
+	}
+}
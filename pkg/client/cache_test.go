@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/port-experimental/port-go-sdk/pkg/cache"
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+)
+
+func TestClientCacheInvalidatesOnWrite(t *testing.T) {
+	gets := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+	cfg := config.Config{APIToken: "token"}
+	cfg.BaseURL = srv.URL
+	c, err := New(cfg, WithCache(cache.Options{}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	ctx := context.Background()
+	path := "/v1/blueprints/svc/entities/api"
+	for i := 0; i < 3; i++ {
+		var out struct{ OK bool }
+		if err := c.Do(ctx, http.MethodGet, path, nil, &out); err != nil || !out.OK {
+			t.Fatalf("get: %v %+v", err, out)
+		}
+	}
+	if gets != 1 {
+		t.Fatalf("expected 1 upstream GET, got %d", gets)
+	}
+	if err := c.Do(ctx, http.MethodPost, "/v1/blueprints/svc/entities/search", map[string]any{}, nil); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil); err != nil || gets != 1 {
+		t.Fatalf("search must not invalidate: gets=%d err=%v", gets, err)
+	}
+	if err := c.Do(ctx, http.MethodPatch, path, map[string]any{}, nil); err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil); err != nil || gets != 2 {
+		t.Fatalf("expected refetch after patch: gets=%d err=%v", gets, err)
+	}
+	if err := c.Do(ctx, http.MethodGet, "/v1/blueprints/svc/entities/api/relations", nil, nil); err != nil || gets != 3 {
+		t.Fatalf("uncached route: gets=%d err=%v", gets, err)
+	}
+	if s := c.CacheStats(); s.Hits != 3 || s.Size != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
@@ -14,20 +14,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/port-experimental/port-go-sdk/pkg/auth"
+	"github.com/port-experimental/port-go-sdk/pkg/breaker"
+	"github.com/port-experimental/port-go-sdk/pkg/cache"
 	"github.com/port-experimental/port-go-sdk/pkg/config"
 	"github.com/port-experimental/port-go-sdk/pkg/httpx"
 	"github.com/port-experimental/port-go-sdk/pkg/porter"
+	"github.com/port-experimental/port-go-sdk/pkg/ratelimit"
+	"github.com/port-experimental/port-go-sdk/pkg/telemetry"
 	"github.com/port-experimental/port-go-sdk/pkg/version"
 )
 
@@ -37,12 +41,28 @@ type Client struct {
 	hc            httpx.Doer
 	tokenSource   auth.TokenSource
 	userAgent     string
-	verbose       bool
-	logger        *log.Logger
+	logger        *slog.Logger
+	logOpts       LogOptions
 	logFile       *os.File // Track file handle for cleanup
 	respLimit     int64
+	respLimitSet  bool
 	bufferPool    sync.Pool
 	retryAttempts int // Number of retry attempts for failed requests
+	retryPolicy   httpx.RetryPolicy
+	cache         *cache.Cache[json.RawMessage]
+	middleware    []Middleware
+	stack         func([]Middleware) []Middleware
+	roundTrip     RoundTripFunc
+	rateDefault   *routeLimit
+	rateRoutes    []routeLimit
+	rateLimit     *ratelimit.Group
+	breaker       *breaker.Breaker
+	tracer        telemetry.Tracer
+	meter         telemetry.Meter
+	credsOpts     auth.ClientCredentialsOptions
+	tokenCache    *auth.TokenCacheOptions
+	// closeTokenSource stops a token source created by the client.
+	closeTokenSource func() error
 }
 
 // Option mutates the Client.
@@ -69,6 +89,13 @@ func WithRetryAttempts(attempts int) Option {
 	}
 }
 
+// WithRetryPolicy replaces the retry policy, for example an
+// *httpx.ExponentialBackoff with a time budget or custom retryable statuses.
+// It takes precedence over WithRetryAttempts.
+func WithRetryPolicy(p httpx.RetryPolicy) Option {
+	return func(c *Client) { c.retryPolicy = p }
+}
+
 // New constructs a Port API client from the provided configuration.
 // The client handles authentication automatically using either an API token
 // or client credentials from the config.
@@ -82,9 +109,6 @@ func WithRetryAttempts(attempts int) Option {
 //	}
 //	defer cli.Close()
 func New(cfg config.Config, opts ...Option) (*Client, error) {
-	if err := cfg.Validate(); err != nil {
-		return nil, err
-	}
 	c := &Client{
 		baseURL:       strings.TrimRight(cfg.BaseEndpoint(), "/"),
 		hc:            httpx.New(),
@@ -101,22 +125,47 @@ func New(cfg config.Config, opts ...Option) (*Client, error) {
 	if c.hc == nil {
 		c.hc = httpx.New()
 	}
-	c.tokenSource = auth.NewTokenSource(cfg, c.hc)
+	if err := c.initTokenSource(cfg); err != nil {
+		return nil, err
+	}
 	c.initVerboseLogger()
 	c.initResponseLimit()
+	if err := c.initRateLimit(); err != nil {
+		return nil, err
+	}
+	c.buildRoundTrip()
 	return c, nil
 }
 
 // Do issues an HTTP request to the Port API and decodes the JSON response
-// into out (if non-nil). It handles authentication, retries, and error handling.
+// into out (if non-nil). The request passes through the middleware chain
+// (user agent, logging, retries and authentication by default; see
+// WithMiddleware) and non-2xx responses are returned as *porter.Error.
 // This method is exported so service packages can invoke Port API endpoints.
 //
 // The context controls the request lifetime. If the context is canceled or
 // times out, the request will be aborted.
 //
 // If out is nil, the response body is discarded. Otherwise, it must be a pointer
-// to a struct that can be unmarshaled from JSON.
+// to a struct that can be unmarshaled from JSON, or a StreamDecoder. Bodies
+// larger than the response limit fail with *ResponseTooLargeError; see
+// WithResponseLimit.
 func (c *Client) Do(ctx context.Context, method, path string, body any, out any) error {
+	if c.cache != nil {
+		if handled, err := c.doCached(ctx, method, path, body, out); handled {
+			return err
+		}
+	}
+	return c.do(ctx, method, path, body, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body any, out any) error {
+	ropts := requestOptionsFrom(ctx)
+	if ropts != nil && ropts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ropts.timeout)
+		defer cancel()
+	}
 	var rdr io.Reader
 	cleanup := func() {}
 	if body != nil {
@@ -136,61 +185,77 @@ func (c *Client) Do(ctx context.Context, method, path string, body any, out any)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	httpx.SetUserAgent(req, c.userAgent)
-	token, err := c.tokenSource.Token(ctx)
+	resp, err := c.send(req, ropts)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	start := time.Now()
-	c.verbosef("--> %s %s", method, path)
-	resp, err := httpx.DoWithRetry(ctx, c.hc, req, c.retryAttempts)
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if sd, ok := out.(StreamDecoder); ok {
+		return sd.DecodeStream(json.NewDecoder(resp.Body))
+	}
+	return json.NewDecoder(c.limitBody(req, resp.Body)).Decode(out)
+}
+
+// send runs req through the middleware chain, applying ropts, and returns
+// error statuses as *porter.Error. The caller closes the response body.
+func (c *Client) send(req *http.Request, ropts *requestOptions) (*http.Response, error) {
+	if ropts != nil {
+		ropts.apply(req)
+	}
+	resp, err := c.roundTrip(req)
 	if err != nil {
-		c.verbosef("<!! %s %s error=%v", method, path, err)
-		return err
+		// Keep the status of the last attempt reachable via porter helpers.
+		var exhausted *httpx.RetryExhaustedError
+		if errors.As(err, &exhausted) && exhausted.StatusCode != 0 {
+			if exhausted.Err == nil {
+				exhausted.Err = apiError(req, nil, exhausted.StatusCode, exhausted.Body)
+			}
+			if ropts != nil && ropts.meta != nil {
+				*ropts.meta = ResponseMeta{StatusCode: exhausted.StatusCode}
+			}
+		}
+		return nil, err
 	}
-	defer resp.Body.Close()
+	ropts.capture(req, resp)
 	if resp.StatusCode >= 300 {
-		payload, readErr := io.ReadAll(resp.Body)
+		defer resp.Body.Close()
+		errBody := io.Reader(resp.Body)
+		if limit := c.responseLimit(req.Context()); limit > 0 {
+			errBody = io.LimitReader(resp.Body, limit)
+		}
+		payload, readErr := io.ReadAll(errBody)
 		if readErr != nil {
 			// If we can't read the error body, still return the HTTP error
 			payload = []byte(fmt.Sprintf("failed to read error body: %v", readErr))
 		}
-		err := &porter.Error{
-			StatusCode: resp.StatusCode,
-			Message:    fmt.Sprintf("port api: %s %s", resp.Status, path),
-			Body:       payload,
-		}
-		c.verbosef("<-- %s %s status=%d duration=%s", method, path, resp.StatusCode, time.Since(start))
-		return err
-	}
-	if out != nil {
-		reader := io.Reader(resp.Body)
-		if c.respLimit > 0 {
-			reader = io.LimitReader(resp.Body, c.respLimit)
-		}
-		err := json.NewDecoder(reader).Decode(out)
-		c.verbosef("<-- %s %s status=%d duration=%s", method, path, resp.StatusCode, time.Since(start))
-		return err
+		return nil, apiError(req, resp, resp.StatusCode, payload)
 	}
-	c.verbosef("<-- %s %s status=%d duration=%s", method, path, resp.StatusCode, time.Since(start))
-	return nil
+	return resp, nil
+}
+
+// apiError decodes an error response to req; resp may be nil when only the
+// status and body survived retries.
+func apiError(req *http.Request, resp *http.Response, code int, body []byte) *porter.Error {
+	e := porter.ParseError(code, body)
+	e.Method = req.Method
+	e.Path = req.URL.EscapedPath()
+	e.Route = RouteTemplate(e.Path)
+	e.RequestID = requestID(req, resp)
+	return e
 }
 
 // Ping ensures credentials are valid by checking the health endpoint.
 // This method is exported for users who want to verify their credentials.
+// Like Do, it runs through the middleware chain.
 func (c *Client) Ping(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/health", http.NoBody)
 	if err != nil {
 		return fmt.Errorf("ping: failed to create request: %w", err)
 	}
-	httpx.SetUserAgent(req, c.userAgent)
-	token, err := c.tokenSource.Token(ctx)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	resp, err := httpx.DoWithRetry(ctx, c.hc, req, 1)
+	resp, err := c.roundTrip(req)
 	if err != nil {
 		return err
 	}
@@ -201,7 +266,12 @@ func (c *Client) Ping(ctx context.Context) error {
 	return nil
 }
 
+// initVerboseLogger honors PORT_SDK_VERBOSE and PORT_SDK_VERBOSE_FILE when no
+// logger was configured, logging requests as text at debug level.
 func (c *Client) initVerboseLogger() {
+	if c.logger != nil {
+		return
+	}
 	raw, ok := os.LookupEnv("PORT_SDK_VERBOSE")
 	if !ok {
 		return
@@ -219,64 +289,24 @@ func (c *Client) initVerboseLogger() {
 			w = f
 		}
 	}
-	c.logger = log.New(w, "[port-sdk] ", log.LstdFlags|log.Lmicroseconds)
-	c.verbose = true
+	c.logger = slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
 }
 
 // Close releases resources held by the client, including log file handles.
 func (c *Client) Close() error {
+	if c.closeTokenSource != nil {
+		_ = c.closeTokenSource()
+	}
 	if c.logFile != nil {
 		return c.logFile.Close()
 	}
 	return nil
 }
 
-func (c *Client) verbosef(format string, args ...any) {
-	if !c.verbose || c.logger == nil {
+func (c *Client) initResponseLimit() {
+	if c.respLimitSet {
 		return
 	}
-	// Sanitize arguments to prevent logging sensitive data
-	sanitized := make([]any, len(args))
-	for i, arg := range args {
-		sanitized[i] = c.sanitizeLogArg(arg)
-	}
-	c.logger.Printf(format, sanitized...)
-}
-
-// sanitizeLogArg removes sensitive information from log arguments.
-func (c *Client) sanitizeLogArg(arg any) any {
-	if arg == nil {
-		return nil
-	}
-	switch v := arg.(type) {
-	case string:
-		// Check for common sensitive patterns
-		if strings.HasPrefix(v, "Bearer ") && len(v) > 20 {
-			return "Bearer [REDACTED]"
-		}
-		if strings.Contains(strings.ToLower(v), "password") ||
-			strings.Contains(strings.ToLower(v), "secret") ||
-			strings.Contains(strings.ToLower(v), "token") ||
-			strings.Contains(strings.ToLower(v), "key") {
-			// Don't log potentially sensitive strings
-			return "[REDACTED]"
-		}
-		return v
-	case error:
-		// Error messages might contain sensitive data
-		errStr := v.Error()
-		if strings.Contains(errStr, "Bearer ") ||
-			strings.Contains(strings.ToLower(errStr), "secret") ||
-			strings.Contains(strings.ToLower(errStr), "token") {
-			return fmt.Errorf("[REDACTED: error may contain sensitive data]")
-		}
-		return v
-	default:
-		return arg
-	}
-}
-
-func (c *Client) initResponseLimit() {
 	if raw := os.Getenv("PORT_SDK_MAX_RESPONSE_BYTES"); raw != "" {
 		if v, err := strconv.ParseInt(raw, 10, 64); err == nil && v > 0 {
 			c.respLimit = v
@@ -2,11 +2,18 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/entities"
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+	"github.com/port-experimental/port-go-sdk/pkg/porter"
 )
 
 func TestClientDo(t *testing.T) {
@@ -30,3 +37,134 @@ func TestClientDo(t *testing.T) {
 		t.Fatalf("do failed: %v", err)
 	}
 }
+
+func TestClientRetryExhausted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c, err := New(config.Config{APIToken: "token", BaseURL: srv.URL},
+		WithRetryPolicy(&httpx.ExponentialBackoff{Attempts: 2, InitialInterval: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	err = c.Do(context.Background(), http.MethodGet, "/v1/test", nil, nil)
+	var exhausted *httpx.RetryExhaustedError
+	if !errors.As(err, &exhausted) || exhausted.Attempts != 2 {
+		t.Fatalf("expected RetryExhaustedError, got %v", err)
+	}
+	if !porter.IsServerError(err) {
+		t.Fatalf("expected porter server error in chain, got %v", err)
+	}
+}
+
+func TestClientErrorPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-9")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"ok":false,"error":"not_found","message":"Blueprint with identifier \"svc\" was not found"}`))
+	}))
+	defer srv.Close()
+	c, err := New(config.Config{APIToken: "token", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	err = c.Do(context.Background(), http.MethodGet, "/v1/blueprints/svc/entities/api", nil, nil)
+	var perr *porter.Error
+	if !errors.Is(err, porter.ErrNotFound) || !errors.As(err, &perr) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if perr.Code != "not_found" || perr.Method != http.MethodGet || perr.RequestID != "req-9" ||
+		perr.Route != "/v1/blueprints/{blueprint}/entities/{entity}" {
+		t.Fatalf("error fields %+v", perr)
+	}
+}
+
+func TestClientResponseLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/entities/search" {
+			var req struct{ From string }
+			json.NewDecoder(r.Body).Decode(&req)
+			next := `"page2"`
+			if req.From != "" {
+				next = "null"
+			}
+			w.Write([]byte(`{"ok":true,"entities":[{"identifier":"a"},{"identifier":"b"}],"next":` + next + `}`))
+			return
+		}
+		w.Write([]byte(`{"identifier":"0123456789"}`))
+	}))
+	defer srv.Close()
+	c, err := New(config.Config{APIToken: "token", BaseURL: srv.URL}, WithResponseLimit(16))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	var out map[string]any
+	err = c.Do(context.Background(), http.MethodGet, "/v1/test", nil, &out)
+	var tooLarge *ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 16 {
+		t.Fatalf("expected ResponseTooLargeError, got %v", err)
+	}
+	ctx := ContextWithResponseLimit(context.Background(), 64)
+	if err := c.Do(ctx, http.MethodGet, "/v1/test", nil, &out); err != nil || out["identifier"] != "0123456789" {
+		t.Fatalf("override: %v %v", err, out)
+	}
+
+	// Streaming decode is not bound by the limit.
+	var ids []string
+	err = c.Entities().ForEach(context.Background(), entities.SearchOptions{Limit: 2}, func(e entities.Entity) error {
+		ids = append(ids, e.Identifier)
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(ids, []string{"a", "b", "a", "b"}) {
+		t.Fatalf("for each: %v %v", err, ids)
+	}
+}
+
+type fixedToken string
+
+func (f fixedToken) Token(context.Context) (string, error) { return string(f), nil }
+
+func TestClientWithTokenSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer vault" {
+			t.Errorf("auth header %q", got)
+		}
+	}))
+	defer srv.Close()
+	// No credentials in the config: the token source replaces them.
+	c, err := New(config.Config{BaseURL: srv.URL}, WithTokenSource(fixedToken("vault")))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer c.Close()
+	if err := c.Do(context.Background(), http.MethodGet, "/v1/test", nil, nil); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if _, ok := c.TokenInfo(); ok {
+		t.Fatal("expected no token info from a plain token source")
+	}
+
+	c, err = New(config.Config{APIToken: "token", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if info, ok := c.TokenInfo(); !ok || !info.Valid {
+		t.Fatalf("token info %+v %v", info, ok)
+	}
+	if _, err := New(config.Config{BaseURL: srv.URL}); err == nil {
+		t.Fatal("expected missing credentials error")
+	}
+}
+
+func TestClientRejectsNonPositiveRateLimit(t *testing.T) {
+	cfg := config.Config{APIToken: "token", BaseURL: "http://localhost"}
+	for _, opt := range []Option{WithRateLimit(0, 5), WithRouteRateLimit("/v1/blueprints", -1, 5)} {
+		if _, err := New(cfg, opt); err == nil {
+			t.Fatal("expected error for non-positive rps")
+		}
+	}
+	if _, err := New(cfg, WithRateLimit(5, 5)); err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+}
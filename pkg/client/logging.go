@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+)
+
+// LogOptions configure request logging.
+type LogOptions struct {
+	// Level is used for completed requests. Defaults to slog.LevelDebug.
+	Level slog.Leveler
+	// ErrorLevel is used for transport errors and 5xx responses. Defaults
+	// to slog.LevelWarn.
+	ErrorLevel slog.Leveler
+	// LogBodies adds JSON request and response bodies as "request_body" and
+	// "response_body" attributes, with secret fields redacted. Non-JSON
+	// bodies and bodies above MaxBodyBytes are reported by size only.
+	LogBodies bool
+	// MaxBodyBytes bounds logged bodies. Defaults to 4096.
+	MaxBodyBytes int
+	// RedactFields adds JSON field names (case-insensitive) to redact in
+	// logged bodies, on top of DefaultRedactFields.
+	RedactFields []string
+}
+
+// DefaultRedactFields are JSON fields whose values are never logged.
+var DefaultRedactFields = []string{
+	"accessToken", "refreshToken", "token", "clientSecret", "secret",
+	"password", "apiKey", "authorization",
+}
+
+// redacted replaces secret values in logged bodies.
+const redacted = "[REDACTED]"
+
+// WithLogger logs every request attempt to logger as structured attributes:
+// method, route template, status, duration, attempt and request ID. Use a
+// slog.JSONHandler for JSON logs.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) { c.logger = logger }
+}
+
+// WithLogOptions sets levels and body logging for WithLogger.
+func WithLogOptions(opts LogOptions) Option {
+	return func(c *Client) { c.logOpts = opts }
+}
+
+// LoggingMiddleware logs each request to logger. Placed inside retry (as in
+// the default stack), every attempt is logged with its attempt number.
+func LoggingMiddleware(logger *slog.Logger, opts LogOptions) Middleware {
+	level, errorLevel := slog.LevelDebug, slog.LevelWarn
+	if opts.Level != nil {
+		level = opts.Level.Level()
+	}
+	if opts.ErrorLevel != nil {
+		errorLevel = opts.ErrorLevel.Level()
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 4096
+	}
+	redact := map[string]bool{}
+	for _, f := range append(append([]string{}, DefaultRedactFields...), opts.RedactFields...) {
+		redact[strings.ToLower(f)] = true
+	}
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			if !logger.Enabled(ctx, level) && !logger.Enabled(ctx, errorLevel) {
+				return next(req)
+			}
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("route", RouteTemplate(req.URL.EscapedPath())),
+			}
+			if n := httpx.Attempt(ctx); n > 0 {
+				attrs = append(attrs, slog.Int("attempt", n))
+			}
+			if opts.LogBodies && req.Body != nil && req.Body != http.NoBody {
+				var attr slog.Attr
+				attr, req.Body = bodyAttr("request_body", req.Body, opts.MaxBodyBytes, redact)
+				attrs = append(attrs, attr)
+			}
+			start := time.Now()
+			resp, err := next(req)
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+			lvl := level
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				lvl = errorLevel
+			} else {
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+				if id := requestID(req, resp); id != "" {
+					attrs = append(attrs, slog.String("request_id", id))
+				}
+				if resp.StatusCode >= 500 {
+					lvl = errorLevel
+				}
+				if opts.LogBodies && resp.Body != nil {
+					var attr slog.Attr
+					attr, resp.Body = bodyAttr("response_body", resp.Body, opts.MaxBodyBytes, redact)
+					attrs = append(attrs, attr)
+				}
+			}
+			logger.LogAttrs(ctx, lvl, "port api request", attrs...)
+			return resp, err
+		}
+	}
+}
+
+// RouteTemplate replaces identifiers in an API path with placeholders named
+// after their collection, for example /v1/blueprints/service/entities/api
+// becomes /v1/blueprints/{blueprint}/entities/{entity}, so logs and metrics
+// aggregate by route.
+func RouteTemplate(path string) string {
+	route, _ := parseRoute(path)
+	return route
+}
+
+// routeParam is an identifier replaced by RouteTemplate.
+type routeParam struct {
+	name, value string
+}
+
+// parseRoute returns the route template of path and the identifiers it
+// replaced, in path order.
+func parseRoute(path string) (string, []routeParam) {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	start := 0
+	if len(segs) > 0 && segs[0] == "v1" {
+		start = 1
+	}
+	var params []routeParam
+	collection := ""
+	for i := start; i < len(segs); i++ {
+		if collection != "" && !routeLiterals[segs[i]] {
+			name := singular(collection)
+			if v, err := url.PathUnescape(segs[i]); err == nil {
+				params = append(params, routeParam{name, v})
+			}
+			segs[i] = "{" + name + "}"
+			collection = ""
+			continue
+		}
+		collection = segs[i]
+	}
+	return "/" + strings.Join(segs, "/"), params
+}
+
+// routeLiterals are path segments that never hold an identifier.
+var routeLiterals = map[string]bool{
+	"search": true, "bulk": true, "aggregate": true, "aggregate-over-time": true,
+	"properties-history": true, "all-entities": true, "config": true,
+	"access_token": true, "runs": true, "entities": true, "scorecards": true,
+	"permissions": true, "logs": true, "approval": true, "invite": true,
+}
+
+func singular(collection string) string {
+	switch {
+	case strings.HasSuffix(collection, "ies"):
+		return strings.TrimSuffix(collection, "ies") + "y"
+	case strings.HasSuffix(collection, "s"):
+		return strings.TrimSuffix(collection, "s")
+	}
+	return collection
+}
+
+func requestID(req *http.Request, resp *http.Response) string {
+	if resp == nil {
+		return req.Header.Get("X-Request-Id")
+	}
+	for _, h := range []string{"X-Request-Id", "X-Port-Request-Id", "X-Amzn-Trace-Id"} {
+		if id := resp.Header.Get(h); id != "" {
+			return id
+		}
+	}
+	return req.Header.Get("X-Request-Id")
+}
+
+// bodyAttr reads up to limit bytes of body for logging and returns a body
+// that replays them followed by the rest of the stream.
+func bodyAttr(key string, body io.ReadCloser, limit int, redact map[string]bool) (slog.Attr, io.ReadCloser) {
+	buf, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	replay := readCloser{io.MultiReader(bytes.NewReader(buf), body), body}
+	if err != nil {
+		return slog.String(key, "<unreadable: "+err.Error()+">"), replay
+	}
+	if len(buf) > limit {
+		return slog.String(key, "<truncated: more than "+strconv.Itoa(limit)+" bytes>"), replay
+	}
+	var v any
+	if len(buf) == 0 || json.Unmarshal(buf, &v) != nil {
+		return slog.String(key, "<"+strconv.Itoa(len(buf))+" bytes, not JSON>"), replay
+	}
+	return slog.Any(key, redactJSON(v, redact)), replay
+}
+
+// redactJSON replaces the values of redacted fields anywhere in v.
+func redactJSON(v any, redact map[string]bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if redact[strings.ToLower(k)] {
+				t[k] = redacted
+			} else {
+				t[k] = redactJSON(val, redact)
+			}
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = redactJSON(val, redact)
+		}
+	}
+	return v
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
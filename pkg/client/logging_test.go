@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+)
+
+func TestStructuredLogging(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.Write([]byte(`{"ok":true,"webhook":{"identifier":"gh","security":{"secret":"s3cr3t"}}}`))
+	}))
+	defer srv.Close()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c, err := New(config.Config{APIToken: "token", BaseURL: srv.URL},
+		WithLogger(logger),
+		WithLogOptions(LogOptions{LogBodies: true, RedactFields: []string{"apiToken"}}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	var out struct {
+		OK      bool `json:"ok"`
+		Webhook struct {
+			Security struct {
+				Secret string `json:"secret"`
+			} `json:"security"`
+		} `json:"webhook"`
+	}
+	body := map[string]any{"identifier": "gh", "apiToken": "abc", "title": "token rotation"}
+	if err := c.Do(context.Background(), http.MethodPost, "/v1/webhooks/gh", body, &out); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if out.Webhook.Security.Secret != "s3cr3t" {
+		t.Fatal("logging must not alter the response body")
+	}
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("log is not a single JSON record: %v\n%s", err, buf.String())
+	}
+	for key, want := range map[string]any{
+		"method": "POST", "route": "/v1/webhooks/{webhook}", "status": float64(200),
+		"attempt": float64(1), "request_id": "req-1", "level": "DEBUG",
+	} {
+		if rec[key] != want {
+			t.Errorf("%s = %v, want %v", key, rec[key], want)
+		}
+	}
+	reqBody := rec["request_body"].(map[string]any)
+	if reqBody["apiToken"] != redacted || reqBody["title"] != "token rotation" {
+		t.Errorf("request body %v", reqBody)
+	}
+	security := rec["response_body"].(map[string]any)["webhook"].(map[string]any)["security"].(map[string]any)
+	if security["secret"] != redacted {
+		t.Errorf("response secret not redacted: %v", security)
+	}
+}
+
+func TestRouteTemplate(t *testing.T) {
+	cases := map[string]string{
+		"/v1/blueprints/service/entities/api":    "/v1/blueprints/{blueprint}/entities/{entity}",
+		"/v1/blueprints/service/entities/search": "/v1/blueprints/{blueprint}/entities/search",
+		"/v1/actions/runs/r_1":                   "/v1/actions/runs/{run}",
+		"/v1/integration/k8s/config":             "/v1/integration/{integration}/config",
+		"/v1/auth/access_token":                  "/v1/auth/access_token",
+	}
+	for in, want := range cases {
+		if got := RouteTemplate(in); got != want {
+			t.Errorf("RouteTemplate(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/port-experimental/port-go-sdk/pkg/auth"
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+)
+
+// RoundTripFunc sends a single API request and returns its response. It
+// satisfies httpx.Doer, so a RoundTripFunc can also be passed to
+// WithHTTPClient.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f RoundTripFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a RoundTripFunc to observe or modify requests and
+// responses, for example to add tracing, auditing, header injection, request
+// signing or fault injection. A middleware that returns a non-nil response
+// owns its body until it hands it back to the caller.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Chain composes middlewares so that mw[0] is the outermost.
+func Chain(mw ...Middleware) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		for i := len(mw) - 1; i >= 0; i-- {
+			next = mw[i](next)
+		}
+		return next
+	}
+}
+
+// WithMiddleware installs middlewares around every request, outside the
+// default middlewares, so they observe each logical call once. Middlewares
+// run in the order given; repeated calls append.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) { c.middleware = append(c.middleware, mw...) }
+}
+
+// WithMiddlewareStack replaces the default middlewares. fn receives them
+// outermost first — telemetry, user agent, circuit breaker, retry, rate
+// limit, logging, auth, where telemetry, the breaker, rate limit and logging
+// are only present when configured — and returns the stack to install, so
+// defaults can be reordered, dropped or replaced and custom middlewares
+// placed between them (for example inside retry to run once per attempt).
+// Middlewares added with WithMiddleware still run outside the returned stack.
+func WithMiddlewareStack(fn func(defaults []Middleware) []Middleware) Option {
+	return func(c *Client) { c.stack = fn }
+}
+
+// UserAgentMiddleware sets the User-Agent header unless the request already
+// has one.
+func UserAgentMiddleware(ua string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			httpx.SetUserAgent(req, ua)
+			return next(req)
+		}
+	}
+}
+
+// AuthMiddleware sets the bearer token from ts on every request. When ts is
+// an auth.InvalidatingTokenSource and the API answers 401, the token is
+// dropped and the request is sent once more with a fresh one, provided its
+// body can be replayed.
+func AuthMiddleware(ts auth.TokenSource) Middleware {
+	inv, canInvalidate := ts.(auth.InvalidatingTokenSource)
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			token, err := ts.Token(req.Context())
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := next(req)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized || !canInvalidate {
+				return resp, err
+			}
+			retry := replayable(req)
+			if retry == nil {
+				return resp, nil
+			}
+			inv.Invalidate(token)
+			fresh, err := ts.Token(req.Context())
+			if err != nil || fresh == token {
+				return resp, nil
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+			retry.Header.Set("Authorization", "Bearer "+fresh)
+			return next(retry)
+		}
+	}
+}
+
+// replayable returns a copy of a sent request with a fresh body, or nil when
+// the body cannot be recreated.
+func replayable(req *http.Request) *http.Request {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry
+	}
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	retry.Body = body
+	return retry
+}
+
+// RetryMiddleware retries failed requests according to policy, honoring
+// Retry-After. A nil policy uses httpx.DefaultRetryPolicy(3). See
+// httpx.DoWithPolicy.
+func RetryMiddleware(policy httpx.RetryPolicy) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		next = countAttempts(next)
+		return func(req *http.Request) (*http.Response, error) {
+			return httpx.DoWithPolicy(req.Context(), next, req, policy)
+		}
+	}
+}
+
+// defaultMiddleware returns the built-in stack, outermost first.
+func (c *Client) defaultMiddleware() []Middleware {
+	var mw []Middleware
+	if c.tracer != nil || c.meter != nil {
+		mw = append(mw, TelemetryMiddleware(c.tracer, c.meter))
+	}
+	mw = append(mw, UserAgentMiddleware(c.userAgent))
+	if c.breaker != nil {
+		mw = append(mw, CircuitBreakerMiddleware(c.breaker))
+	}
+	mw = append(mw, RetryMiddleware(c.retry()))
+	if c.rateLimit != nil {
+		mw = append(mw, RateLimitMiddleware(c.rateLimit))
+	}
+	if c.logger != nil {
+		mw = append(mw, LoggingMiddleware(c.logger, c.logOpts))
+	}
+	return append(mw, AuthMiddleware(c.tokenSource))
+}
+
+func (c *Client) retry() httpx.RetryPolicy {
+	if c.retryPolicy != nil {
+		return c.retryPolicy
+	}
+	return httpx.DefaultRetryPolicy(c.retryAttempts)
+}
+
+// buildRoundTrip composes user middlewares, the (possibly customized)
+// default stack and the HTTP client.
+func (c *Client) buildRoundTrip() {
+	stack := c.defaultMiddleware()
+	if c.stack != nil {
+		stack = c.stack(stack)
+	}
+	all := append(append([]Middleware{}, c.middleware...), stack...)
+	c.roundTrip = Chain(all...)(c.hc.Do)
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/port-experimental/port-go-sdk/pkg/auth"
+	"github.com/port-experimental/port-go-sdk/pkg/breaker"
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/porter"
+)
+
+// tag returns a middleware recording its name on the way in.
+func tag(name string, calls *[]string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name)
+			req.Header.Add("X-Chain", name)
+			return next(req)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if got := r.Header.Values("X-Chain"); !reflect.DeepEqual(got, []string{"outer", "inner"}) {
+			t.Errorf("chain headers %v", got)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("missing auth header")
+		}
+	}))
+	defer srv.Close()
+	cfg := config.Config{APIToken: "token", BaseURL: srv.URL}
+
+	var calls []string
+	c, err := New(cfg,
+		WithMiddleware(tag("outer", &calls)),
+		WithMiddlewareStack(func(defaults []Middleware) []Middleware {
+			// Run "inner" once per attempt, inside retry.
+			return append(defaults, tag("inner", &calls))
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if err := c.Do(context.Background(), http.MethodGet, "/v1/test", nil, nil); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if want := []string{"outer", "inner", "inner"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	boom := errors.New("chaos")
+	c, err := New(config.Config{APIToken: "token", BaseURL: "http://127.0.0.1:0"},
+		WithMiddleware(func(RoundTripFunc) RoundTripFunc {
+			return func(*http.Request) (*http.Response, error) { return nil, boom }
+		}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if err := c.Do(context.Background(), http.MethodGet, "/v1/test", nil, nil); !errors.Is(err, boom) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	c, err := New(config.Config{APIToken: "token", BaseURL: srv.URL},
+		WithRetryAttempts(1),
+		WithCircuitBreaker(breaker.Options{ConsecutiveFailures: 2}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		err = c.Do(context.Background(), http.MethodGet, "/v1/blueprints/service/entities/api", nil, nil)
+	}
+	if !errors.Is(err, ErrCircuitOpen) || atomic.LoadInt32(&hits) != 2 {
+		t.Fatalf("expected open circuit after 2 calls, got %v (hits=%d)", err, hits)
+	}
+	if states := c.CircuitStates(); states["entities"] != breaker.StateOpen {
+		t.Fatalf("states %v", states)
+	}
+}
+
+func TestAuthRefreshOn401(t *testing.T) {
+	var exchanges, calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/access_token" {
+			n := atomic.AddInt32(&exchanges, 1)
+			fmt.Fprintf(w, `{"accessToken":"t%d","expiresIn":3600}`, n)
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		// t1 was revoked server-side.
+		if r.Header.Get("Authorization") != "Bearer t2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer srv.Close()
+	c, err := New(config.Config{ClientID: "id", ClientSecret: "secret", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	var out map[string]string
+	if err := c.Do(context.Background(), http.MethodPost, "/v1/echo", map[string]string{"k": "v"}, &out); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if out["k"] != "v" || exchanges != 2 || calls != 2 {
+		t.Fatalf("out %v, exchanges %d, calls %d", out, exchanges, calls)
+	}
+
+	// A token that keeps failing is retried only once.
+	atomic.StoreInt32(&calls, 0)
+	c.tokenSource.(auth.InvalidatingTokenSource).Invalidate("t2")
+	err = c.Do(context.Background(), http.MethodGet, "/v1/test", nil, nil)
+	if !porter.IsUnauthorized(err) || calls != 2 {
+		t.Fatalf("expected 401 after one retry, got %v (calls=%d)", err, calls)
+	}
+}
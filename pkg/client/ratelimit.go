@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/port-experimental/port-go-sdk/pkg/ratelimit"
+)
+
+type routeLimit struct {
+	pattern string
+	rps     float64
+	burst   int
+}
+
+// limiter builds the bucket, rejecting rates that would never delay a
+// request once the burst is spent.
+func (r routeLimit) limiter() (*ratelimit.Limiter, error) {
+	l, err := ratelimit.New(r.rps, r.burst)
+	if err != nil {
+		name := r.pattern
+		if name == "" {
+			name = ratelimit.DefaultRoute
+		}
+		return nil, fmt.Errorf("client: rate limit %q: %w", name, err)
+	}
+	return l, nil
+}
+
+// WithRateLimit limits every request made through the client to rps requests
+// per second with bursts of up to burst. The limiter slows down on 429
+// responses and exhausted rate-limit headers and recovers as requests
+// succeed. See RateLimitStats for wait-time metrics. New returns an error if
+// rps is not positive.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) { c.rateDefault = &routeLimit{rps: rps, burst: burst} }
+}
+
+// WithRouteRateLimit gives requests matching route their own bucket, for
+// example "POST /v1/blueprints/*/entities/search" ("*" matches one path
+// segment). Requests still count against WithRateLimit when both are set.
+// New returns an error if rps is not positive.
+func WithRouteRateLimit(route string, rps float64, burst int) Option {
+	return func(c *Client) {
+		c.rateRoutes = append(c.rateRoutes, routeLimit{pattern: route, rps: rps, burst: burst})
+	}
+}
+
+// RateLimitStats returns limiter counters keyed by route pattern, with the
+// WithRateLimit bucket under ratelimit.DefaultRoute. It returns nil when rate
+// limiting is disabled.
+func (c *Client) RateLimitStats() map[string]ratelimit.Stats {
+	if c.rateLimit == nil {
+		return nil
+	}
+	return c.rateLimit.Stats()
+}
+
+// RateLimitMiddleware waits for g before each attempt and feeds responses
+// back so the limiters adapt.
+func RateLimitMiddleware(g *ratelimit.Group) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			waited, err := g.Wait(req)
+			if info := callInfoFrom(req.Context()); info != nil {
+				info.rateWait += waited
+			}
+			if err != nil {
+				return nil, err
+			}
+			resp, err := next(req)
+			if err == nil {
+				g.Observe(req, resp)
+			}
+			return resp, err
+		}
+	}
+}
+
+func (c *Client) initRateLimit() error {
+	if c.rateDefault == nil && len(c.rateRoutes) == 0 {
+		return nil
+	}
+	var def *ratelimit.Limiter
+	if c.rateDefault != nil {
+		l, err := c.rateDefault.limiter()
+		if err != nil {
+			return err
+		}
+		def = l
+	}
+	g := ratelimit.NewGroup(def)
+	for _, r := range c.rateRoutes {
+		l, err := r.limiter()
+		if err != nil {
+			return err
+		}
+		if err := g.Route(r.pattern, l); err != nil {
+			return err
+		}
+	}
+	c.rateLimit = g
+	return nil
+}
@@ -0,0 +1,209 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Request describes a raw API call for endpoints the SDK does not cover yet.
+// Build one with NewRequest and send it with Client.Send:
+//
+//	req := client.NewRequest(http.MethodGet, "/v1/blueprints/{blueprint}/entities/{entity}").
+//		Param("blueprint", "service").
+//		Param("entity", id).
+//		Query("include", "properties")
+//	resp, err := cli.Send(ctx, req)
+type Request struct {
+	method      string
+	path        string
+	params      map[string]string
+	query       url.Values
+	header      http.Header
+	body        io.Reader
+	jsonBody    any
+	contentType string
+}
+
+// NewRequest returns a request for method and a path template whose
+// {placeholders} are filled by Param.
+func NewRequest(method, path string) *Request {
+	return &Request{
+		method: method,
+		path:   path,
+		params: map[string]string{},
+		query:  url.Values{},
+		header: http.Header{},
+	}
+}
+
+// Param sets the value of the {name} placeholder in the path. Values are
+// path-escaped.
+func (r *Request) Param(name, value string) *Request {
+	r.params[name] = value
+	return r
+}
+
+// Query adds a query parameter.
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Header sets a request header.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// JSON sets v, encoded as JSON, as the request body.
+func (r *Request) JSON(v any) *Request {
+	r.jsonBody, r.body = v, nil
+	return r
+}
+
+// Body sets a raw request body with the given content type.
+func (r *Request) Body(body io.Reader, contentType string) *Request {
+	r.body, r.jsonBody, r.contentType = body, nil, contentType
+	return r
+}
+
+// URL returns the request path with placeholders filled and the query
+// appended, relative to the API base URL.
+func (r *Request) URL() (string, error) {
+	var b strings.Builder
+	rest := r.path
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("client: request path %q: unclosed placeholder", r.path)
+		}
+		name := rest[open+1 : open+end]
+		value, ok := r.params[name]
+		if !ok {
+			return "", fmt.Errorf("client: request path %q: missing param %q", r.path, name)
+		}
+		b.WriteString(rest[:open])
+		b.WriteString(url.PathEscape(value))
+		rest = rest[open+end+1:]
+	}
+	if len(r.query) > 0 {
+		b.WriteString("?" + r.query.Encode())
+	}
+	return b.String(), nil
+}
+
+// Send issues req through the middleware chain and returns the response for
+// the caller to read and close; opts apply on top of any request options in
+// ctx. Non-2xx responses are returned as *porter.Error, as with Do. Send
+// does not use or invalidate the read cache.
+func (c *Client) Send(ctx context.Context, req *Request, opts ...RequestOption) (*Response, error) {
+	path, err := req.URL()
+	if err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	contentType := req.contentType
+	switch {
+	case req.jsonBody != nil:
+		b, err := json.Marshal(req.jsonBody)
+		if err != nil {
+			return nil, fmt.Errorf("client: encode request body: %w", err)
+		}
+		body, contentType = bytes.NewReader(b), "application/json"
+	case req.body != nil:
+		body = req.body
+	}
+	if len(opts) > 0 {
+		ctx = ContextWithRequestOptions(ctx, opts...)
+	}
+	ropts := requestOptionsFrom(ctx)
+	cancel := context.CancelFunc(func() {})
+	if ropts != nil && ropts.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, ropts.timeout)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+path, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for k, v := range req.header {
+		httpReq.Header[k] = v
+	}
+	if contentType != "" && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.send(httpReq, ropts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The timeout covers reading the body, so cancel on Close.
+	resp.Body = cancelOnClose{resp.Body, cancel}
+	return &Response{Response: resp, client: c, req: httpReq}, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Response is a successful response to Client.Send. Decode, Bytes and
+// WriteTo consume and close the body; otherwise call Close.
+type Response struct {
+	*http.Response
+	client *Client
+	req    *http.Request
+}
+
+// RequestID returns the request ID reported by Port, if any.
+func (r *Response) RequestID() string {
+	return requestID(r.req, r.Response)
+}
+
+// Decode decodes a JSON body into v, subject to the response limit. An
+// empty body leaves v unchanged.
+func (r *Response) Decode(v any) error {
+	defer r.Body.Close()
+	err := json.NewDecoder(r.client.limitBody(r.req, r.Body)).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// Bytes returns the body, subject to the response limit.
+func (r *Response) Bytes() ([]byte, error) {
+	defer r.Body.Close()
+	return io.ReadAll(r.client.limitBody(r.req, r.Body))
+}
+
+// WriteTo streams the body to w, for example a file, without applying the
+// response limit.
+func (r *Response) WriteTo(w io.Writer) (int64, error) {
+	defer r.Body.Close()
+	return io.Copy(w, r.Body)
+}
+
+// Close discards the rest of the body and closes it.
+func (r *Response) Close() error {
+	_, _ = io.Copy(io.Discard, io.LimitReader(r.Body, 64<<10))
+	return r.Body.Close()
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/ratelimit"
+)
+
+// RequestOption customizes a single API call. Service methods only take a
+// context, so options travel in it: pass the context returned by
+// ContextWithRequestOptions to Do or to any service method.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header  http.Header
+	query   url.Values
+	timeout time.Duration
+	meta    *ResponseMeta
+}
+
+// ResponseMeta describes the response to a call made with WithResponse.
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+	RequestID  string
+	// RateLimit is the quota reported by the response, if HasRateLimit.
+	RateLimit    ratelimit.Quota
+	HasRateLimit bool
+}
+
+// WithHeader sets a request header, replacing any value set by the client.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) { o.header.Set(key, value) }
+}
+
+// WithQuery adds a query parameter to the request URL.
+func WithQuery(key, value string) RequestOption {
+	return func(o *requestOptions) { o.query.Add(key, value) }
+}
+
+// WithRunID attributes the call to an action run, so Port links entities
+// created or updated by it to the run (the run_id query parameter).
+func WithRunID(runID string) RequestOption {
+	return WithQuery("run_id", runID)
+}
+
+// WithTimeout bounds the call, including retries and waits, to d.
+func WithTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) { o.timeout = d }
+}
+
+// WithResponse fills meta with the status, headers, request ID and rate-limit
+// quota of the final response, including error responses.
+func WithResponse(meta *ResponseMeta) RequestOption {
+	return func(o *requestOptions) { o.meta = meta }
+}
+
+type requestOptionsKey struct{}
+
+// ContextWithRequestOptions returns a context that applies opts to calls
+// made with it, after any options already in ctx. Reads made with request
+// options bypass the read cache.
+//
+//	var meta client.ResponseMeta
+//	ctx := client.ContextWithRequestOptions(ctx, client.WithRunID(runID), client.WithResponse(&meta))
+//	err := cli.Entities().Upsert(ctx, "service", ent)
+func ContextWithRequestOptions(ctx context.Context, opts ...RequestOption) context.Context {
+	prev, _ := ctx.Value(requestOptionsKey{}).([]RequestOption)
+	all := append(append([]RequestOption{}, prev...), opts...)
+	return context.WithValue(ctx, requestOptionsKey{}, all)
+}
+
+// requestOptionsFrom collects the options in ctx, or returns nil.
+func requestOptionsFrom(ctx context.Context) *requestOptions {
+	opts, _ := ctx.Value(requestOptionsKey{}).([]RequestOption)
+	if len(opts) == 0 {
+		return nil
+	}
+	o := &requestOptions{header: http.Header{}, query: url.Values{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// apply sets headers and query parameters on req.
+func (o *requestOptions) apply(req *http.Request) {
+	for k, v := range o.header {
+		req.Header[k] = v
+	}
+	if len(o.query) > 0 {
+		if req.URL.RawQuery != "" {
+			req.URL.RawQuery += "&"
+		}
+		req.URL.RawQuery += o.query.Encode()
+	}
+}
+
+// capture fills the WithResponse target from resp.
+func (o *requestOptions) capture(req *http.Request, resp *http.Response) {
+	if o == nil || o.meta == nil {
+		return
+	}
+	*o.meta = ResponseMeta{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		RequestID:  requestID(req, resp),
+	}
+	o.meta.RateLimit, o.meta.HasRateLimit = ratelimit.ParseQuota(resp.Header, time.Now())
+}
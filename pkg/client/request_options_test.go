@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/entities"
+)
+
+func TestRequestOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/slow" {
+			<-r.Context().Done()
+			return
+		}
+		if got := r.URL.RawQuery; got != "upsert=true&merge=true&run_id=r_123" {
+			t.Errorf("query %q", got)
+		}
+		if r.Header.Get("X-Tenant") != "acme" {
+			t.Errorf("missing header")
+		}
+		w.Header().Set("X-Request-Id", "req-7")
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"ok":true,"entity":{"identifier":"api"}}`))
+	}))
+	defer srv.Close()
+	c, err := New(config.Config{APIToken: "token", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	var meta ResponseMeta
+	ctx := ContextWithRequestOptions(context.Background(), WithHeader("X-Tenant", "acme"))
+	ctx = ContextWithRequestOptions(ctx, WithRunID("r_123"), WithResponse(&meta))
+	if err := c.Entities().Upsert(ctx, "service", entities.Entity{Identifier: "api"}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if meta.StatusCode != http.StatusCreated || meta.RequestID != "req-7" ||
+		!meta.HasRateLimit || meta.RateLimit.Remaining != 42 || meta.RateLimit.Limit != 100 {
+		t.Fatalf("meta %+v", meta)
+	}
+
+	ctx = ContextWithRequestOptions(context.Background(), WithTimeout(20*time.Millisecond))
+	if err := c.Do(ctx, http.MethodGet, "/v1/slow", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
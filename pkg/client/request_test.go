@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/porter"
+)
+
+func TestRequestURL(t *testing.T) {
+	got, err := NewRequest(http.MethodGet, "/v1/blueprints/{blueprint}/entities/{entity}").
+		Param("blueprint", "service").Param("entity", "api/v2").
+		Query("include", "properties").URL()
+	if want := "/v1/blueprints/service/entities/api%2Fv2?include=properties"; err != nil || got != want {
+		t.Fatalf("URL() = %q, %v; want %q", got, err, want)
+	}
+	if _, err := NewRequest(http.MethodGet, "/v1/blueprints/{blueprint}").URL(); err == nil {
+		t.Fatal("expected missing param error")
+	}
+}
+
+func TestSend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/upload":
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get("Content-Type") != "text/csv" || string(body) != "a,b\n" {
+				t.Errorf("upload %s %q", r.Header.Get("Content-Type"), body)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case "/v1/export":
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Write(bytes.Repeat([]byte("x"), 100))
+		case "/v1/echo":
+			if r.URL.Query().Get("run_id") != "r_1" {
+				t.Errorf("missing run_id in %s", r.URL.RawQuery)
+			}
+			io.Copy(w, r.Body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	c, err := New(config.Config{APIToken: "token", BaseURL: srv.URL}, WithResponseLimit(50))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	ctx := context.Background()
+
+	resp, err := c.Send(ctx, NewRequest(http.MethodPost, "/v1/upload").Body(strings.NewReader("a,b\n"), "text/csv"))
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	var out map[string]any
+	if err := resp.Decode(&out); err != nil || out != nil {
+		t.Fatalf("empty body: %v %v", err, out)
+	}
+
+	resp, err = c.Send(ctx, NewRequest(http.MethodPost, "/v1/echo").JSON(map[string]string{"k": "v"}), WithRunID("r_1"))
+	if err != nil {
+		t.Fatalf("echo: %v", err)
+	}
+	if err := resp.Decode(&out); err != nil || out["k"] != "v" {
+		t.Fatalf("decode: %v %v", err, out)
+	}
+
+	// Downloads stream past the response limit.
+	resp, err = c.Send(ctx, NewRequest(http.MethodGet, "/v1/export"))
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	var buf bytes.Buffer
+	if n, err := resp.WriteTo(&buf); err != nil || n != 100 {
+		t.Fatalf("download: %d %v", n, err)
+	}
+
+	if _, err := c.Send(ctx, NewRequest(http.MethodGet, "/v1/missing")); !porter.IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ResponseTooLargeError is returned when a response body exceeds the
+// response limit. The body is not decoded, so out is left incomplete.
+type ResponseTooLargeError struct {
+	Method string
+	Path   string
+	// Limit is the limit that was exceeded, in bytes.
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("port api: %s %s: response exceeds %d bytes; raise the limit with client.WithResponseLimit or client.ContextWithResponseLimit, or use a streaming call such as Entities().ForEach",
+		e.Method, e.Path, e.Limit)
+}
+
+// StreamDecoder is implemented by response targets that decode the body
+// incrementally, for example one array element at a time, instead of
+// through json.Decoder.Decode. Do hands them a decoder over the whole body
+// without applying the response limit, since they need not hold it in
+// memory.
+type StreamDecoder interface {
+	DecodeStream(dec *json.Decoder) error
+}
+
+// WithResponseLimit sets the maximum size of a response body Do will decode,
+// 10 MiB by default (or PORT_SDK_MAX_RESPONSE_BYTES). Zero or less disables
+// the limit. Larger responses fail with *ResponseTooLargeError.
+func WithResponseLimit(n int64) Option {
+	return func(c *Client) {
+		c.respLimit = n
+		c.respLimitSet = true
+	}
+}
+
+type responseLimitKey struct{}
+
+// ContextWithResponseLimit overrides the response limit for calls made with
+// the returned context, including calls made through service packages.
+// Zero or less disables the limit.
+func ContextWithResponseLimit(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, responseLimitKey{}, n)
+}
+
+func (c *Client) responseLimit(ctx context.Context) int64 {
+	if n, ok := ctx.Value(responseLimitKey{}).(int64); ok {
+		return n
+	}
+	return c.respLimit
+}
+
+// limitedReader reads at most limit bytes and fails with err once the
+// underlying reader has more.
+type limitedReader struct {
+	r    io.Reader
+	left int64
+	err  error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, l.err
+	}
+	// Read one byte past the limit to tell a body of exactly limit bytes
+	// from a longer one.
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n + int(l.left), l.err
+	}
+	return n, err
+}
+
+// limitBody bounds body by the response limit for req's context.
+func (c *Client) limitBody(req *http.Request, body io.Reader) io.Reader {
+	limit := c.responseLimit(req.Context())
+	if limit <= 0 {
+		return body
+	}
+	return &limitedReader{r: body, left: limit, err: &ResponseTooLargeError{Method: req.Method, Path: req.URL.Path, Limit: limit}}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+	"github.com/port-experimental/port-go-sdk/pkg/telemetry"
+)
+
+// WithTracer starts a span around every API call, named after the method and
+// route template (for example "GET /v1/blueprints/{blueprint}/entities/{entity}"),
+// with the status, retry count and the identifiers from the path (such as
+// "port.blueprint" and "port.entity") as attributes. Token exchanges get
+// spans of their own. Requests carry the span's context, so an instrumented
+// HTTP client passed to WithHTTPClient can propagate it.
+func WithTracer(t telemetry.Tracer) Option {
+	return func(c *Client) { c.tracer = t }
+}
+
+// WithMeter records request counts, latency, retries and rate-limit waits for
+// every API call. See the telemetry.Metric constants for names.
+func WithMeter(m telemetry.Meter) Option {
+	return func(c *Client) { c.meter = m }
+}
+
+// callInfo collects what inner middlewares learn about a call for
+// TelemetryMiddleware.
+type callInfo struct {
+	attempts int
+	rateWait time.Duration
+}
+
+type callInfoKey struct{}
+
+func callInfoFrom(ctx context.Context) *callInfo {
+	info, _ := ctx.Value(callInfoKey{}).(*callInfo)
+	return info
+}
+
+// TelemetryMiddleware traces and measures each request; tracer or meter may
+// be nil. Placed outside retry (as in the default stack), it sees each call
+// once and reports how many times it was resent. Placed inside retry, it
+// reports every attempt.
+func TelemetryMiddleware(tracer telemetry.Tracer, meter telemetry.Meter) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			route, params := parseRoute(req.URL.EscapedPath())
+			attrs := []telemetry.Attr{
+				telemetry.String(telemetry.AttrMethod, req.Method),
+				telemetry.String(telemetry.AttrRoute, route),
+			}
+			// An outer retry loop (such as the token exchange's) makes this
+			// call one of several attempts.
+			outerAttempt := httpx.Attempt(ctx)
+			info := &callInfo{}
+			ctx = context.WithValue(ctx, callInfoKey{}, info)
+			var span telemetry.Span
+			if tracer != nil {
+				spanAttrs := append([]telemetry.Attr{}, attrs...)
+				for _, p := range params {
+					spanAttrs = append(spanAttrs, telemetry.String(telemetry.AttrPrefix+p.name, p.value))
+				}
+				ctx, span = tracer.Start(ctx, req.Method+" "+route, spanAttrs...)
+				defer span.End()
+			}
+			start := time.Now()
+			resp, err := next(req.WithContext(ctx))
+			elapsed := time.Since(start)
+
+			status := 0
+			var exhausted *httpx.RetryExhaustedError
+			switch {
+			case resp != nil:
+				status = resp.StatusCode
+			case errors.As(err, &exhausted):
+				status = exhausted.StatusCode
+			}
+			var outcome []telemetry.Attr
+			if status != 0 {
+				outcome = append(outcome, telemetry.Int(telemetry.AttrStatusCode, status))
+			}
+			switch {
+			case status >= 400:
+				outcome = append(outcome, telemetry.String(telemetry.AttrErrorType, strconv.Itoa(status)))
+			case err != nil:
+				outcome = append(outcome, telemetry.String(telemetry.AttrErrorType, errorType(err)))
+			}
+			retries := 0
+			switch {
+			case info.attempts > 1:
+				retries = info.attempts - 1
+			case outerAttempt > 1:
+				retries = outerAttempt - 1
+			}
+
+			if span != nil {
+				span.SetAttributes(append(outcome, telemetry.Int(telemetry.AttrRetryCount, retries))...)
+				if err != nil {
+					span.RecordError(err)
+				} else if status >= 400 {
+					span.RecordError(fmt.Errorf("port api: %s", resp.Status))
+				}
+			}
+			if meter != nil {
+				attrs = append(attrs, outcome...)
+				meter.Add(ctx, telemetry.MetricRequests, 1, attrs...)
+				meter.Record(ctx, telemetry.MetricDuration, elapsed.Seconds(), attrs...)
+				// Count each resend once: all of them when the retries ran
+				// inside this call, or this attempt when they run outside.
+				if info.attempts > 1 {
+					meter.Add(ctx, telemetry.MetricRetries, int64(retries), attrs...)
+				} else if outerAttempt > 1 {
+					meter.Add(ctx, telemetry.MetricRetries, 1, attrs...)
+				}
+				if info.rateWait > 0 {
+					meter.Record(ctx, telemetry.MetricRateLimitWait, info.rateWait.Seconds(), attrs...)
+				}
+			}
+			return resp, err
+		}
+	}
+}
+
+// errorType classifies transport errors for the error.type attribute.
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	}
+	return "_OTHER"
+}
+
+// countAttempts records each attempt in the call's telemetry.
+func countAttempts(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if info := callInfoFrom(req.Context()); info != nil {
+			info.attempts = httpx.Attempt(req.Context())
+		}
+		return next(req)
+	}
+}
+
+// authDoer is the HTTP client used for token exchanges, instrumented when
+// telemetry is configured.
+func (c *Client) authDoer() httpx.Doer {
+	if c.tracer == nil && c.meter == nil {
+		return c.hc
+	}
+	return TelemetryMiddleware(c.tracer, c.meter)(c.hc.Do)
+}
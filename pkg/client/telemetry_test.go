@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+	"github.com/port-experimental/port-go-sdk/pkg/telemetry"
+)
+
+func TestTelemetry(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/access_token" {
+			w.Write([]byte(`{"accessToken":"tok","expiresIn":3600}`))
+			return
+		}
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	rec := telemetry.NewRecorder()
+	c, err := New(config.Config{ClientID: "id", ClientSecret: "secret", BaseURL: srv.URL},
+		WithTracer(rec), WithMeter(rec),
+		WithRetryPolicy(&httpx.ExponentialBackoff{InitialInterval: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if err := c.Do(context.Background(), http.MethodGet, "/v1/blueprints/service/entities/api%2Fv2", nil, nil); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+
+	spans := rec.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected call and token spans, got %+v", spans)
+	}
+	call, token := spans[0], spans[1]
+	if call.Name != "GET /v1/blueprints/{blueprint}/entities/{entity}" || !call.Ended {
+		t.Fatalf("call span %+v", call)
+	}
+	for key, want := range map[string]any{
+		"port.blueprint": "service", "port.entity": "api/v2",
+		telemetry.AttrStatusCode: int64(200), telemetry.AttrRetryCount: int64(1),
+	} {
+		if call.Attrs[key] != want {
+			t.Errorf("%s = %v, want %v", key, call.Attrs[key], want)
+		}
+	}
+	if token.Name != "POST /v1/auth/access_token" || token.Parent != call.Name {
+		t.Errorf("token span %+v", token)
+	}
+
+	if got := rec.Sum(telemetry.MetricRetries); got != 1 {
+		t.Errorf("retries = %v, want 1", got)
+	}
+	requests := rec.Measurements(telemetry.MetricRequests)
+	if len(requests) != 2 || requests[0].Attrs[telemetry.AttrRoute] != "/v1/auth/access_token" {
+		t.Errorf("requests %+v", requests)
+	}
+	if _, ok := requests[1].Attrs["port.entity"]; ok {
+		t.Error("identifiers must not be metric attributes")
+	}
+	if len(rec.Measurements(telemetry.MetricDuration)) != 2 {
+		t.Error("expected a duration per request")
+	}
+}
@@ -34,12 +34,35 @@ func Load(dotenvPath string) (Config, error) {
 		_ = loadDotEnvFile(".env") //nolint:errcheck // .env file is optional
 	}
 
+	return fromLookup(os.Getenv)
+}
+
+// LoadFile builds a Config from a .env-style file only, ignoring the process
+// environment and leaving it untouched. Use it when one process talks to
+// several organizations, where Load would let the first file's variables win.
+func LoadFile(path string) (Config, error) {
+	vars, err := readDotEnvFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	m := make(map[string]string, len(vars))
+	for _, kv := range vars {
+		m[kv[0]] = kv[1]
+	}
+	return fromLookup(func(key string) string { return m[key] })
+}
+
+func fromLookup(get func(string) string) (Config, error) {
+	region := strings.TrimSpace(get("PORT_REGION"))
+	if region == "" {
+		region = "eu"
+	}
 	cfg := Config{
-		Region:       envOr("PORT_REGION", "eu"),
-		BaseURL:      os.Getenv("PORT_BASE_URL"),
-		ClientID:     strings.TrimSpace(os.Getenv("PORT_CLIENT_ID")),
-		ClientSecret: strings.TrimSpace(os.Getenv("PORT_CLIENT_SECRET")),
-		APIToken:     strings.TrimSpace(os.Getenv("PORT_ACCESS_TOKEN")),
+		Region:       region,
+		BaseURL:      get("PORT_BASE_URL"),
+		ClientID:     strings.TrimSpace(get("PORT_CLIENT_ID")),
+		ClientSecret: strings.TrimSpace(get("PORT_CLIENT_SECRET")),
+		APIToken:     strings.TrimSpace(get("PORT_ACCESS_TOKEN")),
 	}
 	if err := cfg.Validate(); err != nil {
 		return Config{}, err
@@ -72,10 +95,3 @@ func (c Config) BaseEndpoint() string {
 		return fmt.Sprintf("https://api.%s.port.io", strings.ToLower(c.Region))
 	}
 }
-
-func envOr(key, def string) string {
-	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
-		return v
-	}
-	return def
-}
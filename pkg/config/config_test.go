@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -44,3 +45,25 @@ func TestLoadRequiresCreds(t *testing.T) {
 		t.Fatalf("unexpected error once creds set: %v", err)
 	}
 }
+
+func TestLoadFileIgnoresEnvironment(t *testing.T) {
+	t.Setenv("PORT_ACCESS_TOKEN", "from-env")
+	t.Setenv("PORT_CLIENT_ID", "")
+	path := filepath.Join(t.TempDir(), "prod.env")
+	if err := os.WriteFile(path, []byte("PORT_CLIENT_ID=id\nPORT_CLIENT_SECRET='secret'\nPORT_REGION=us\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load file: %v", err)
+	}
+	if cfg.APIToken != "" || cfg.ClientID != "id" || cfg.ClientSecret != "secret" || cfg.Region != "us" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if os.Getenv("PORT_CLIENT_ID") != "" {
+		t.Fatalf("LoadFile must not modify the environment")
+	}
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.env")); err == nil {
+		t.Fatalf("expected error for missing file")
+	}
+}
@@ -10,15 +10,33 @@ import (
 // loadDotEnvFile best-effort loads environment variables from a .env-style file.
 // Missing files are silently ignored, matching godotenv.Load behavior.
 func loadDotEnvFile(path string) error {
-	f, err := os.Open(path)
+	vars, err := readDotEnvFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil
 		}
 		return err
 	}
+	for _, kv := range vars {
+		if _, exists := os.LookupEnv(kv[0]); exists {
+			continue
+		}
+		if err := os.Setenv(kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDotEnvFile parses a .env-style file into key/value pairs in file order.
+func readDotEnvFile(path string) ([][2]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
 	defer f.Close()
 
+	var vars [][2]string
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -40,14 +58,9 @@ func loadDotEnvFile(path string) error {
 		if len(val) >= 2 && isQuoted(val) {
 			val = val[1 : len(val)-1]
 		}
-		if _, exists := os.LookupEnv(key); exists {
-			continue
-		}
-		if err := os.Setenv(key, val); err != nil {
-			return err
-		}
+		vars = append(vars, [2]string{key, val})
 	}
-	return scanner.Err()
+	return vars, scanner.Err()
 }
 
 func isQuoted(s string) bool {
@@ -210,7 +210,17 @@ func (s *Service) ListWebhooks(ctx context.Context) ([]Webhook, error) {
 	if err := s.doer.Do(ctx, "GET", "/v1/webhooks", nil, &raw); err != nil {
 		return nil, err
 	}
-	return decodeWebhookList(raw)
+	return decodeWebhookList[Webhook](raw)
+}
+
+// ListWebhooksRaw returns every webhook definition as sent by the API,
+// including fields Webhook does not model.
+func (s *Service) ListWebhooksRaw(ctx context.Context) ([]json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := s.doer.Do(ctx, "GET", "/v1/webhooks", nil, &raw); err != nil {
+		return nil, err
+	}
+	return decodeWebhookList[json.RawMessage](raw)
 }
 
 // GetWebhook fetches a webhook definition.
@@ -310,10 +320,12 @@ func decodeIntegrationLogs(raw json.RawMessage) (IntegrationLogs, error) {
 	return IntegrationLogs{}, fmt.Errorf("datasources: unexpected integration logs response")
 }
 
-func decodeWebhookList(raw json.RawMessage) ([]Webhook, error) {
+// decodeWebhookList accepts the list shapes returned by the API. T is Webhook,
+// or json.RawMessage to keep fields Webhook does not model.
+func decodeWebhookList[T any](raw json.RawMessage) ([]T, error) {
 	type top struct {
-		Webhooks []Webhook `json:"webhooks"`
-		Items    []Webhook `json:"items"`
+		Webhooks []T `json:"webhooks"`
+		Items    []T `json:"items"`
 	}
 	var wrap top
 	if err := json.Unmarshal(raw, &wrap); err == nil {
@@ -327,30 +339,30 @@ func decodeWebhookList(raw json.RawMessage) ([]Webhook, error) {
 	var envelope map[string]json.RawMessage
 	if err := json.Unmarshal(raw, &envelope); err == nil {
 		if wraw, ok := envelope["webhooks"]; ok {
-			var simple []Webhook
+			var simple []T
 			if err := json.Unmarshal(wraw, &simple); err == nil {
 				return simple, nil
 			}
 			var items struct {
-				Items []Webhook `json:"items"`
+				Items []T `json:"items"`
 			}
 			if err := json.Unmarshal(wraw, &items); err == nil && items.Items != nil {
 				return items.Items, nil
 			}
 		}
 		if itemsRaw, ok := envelope["items"]; ok {
-			var items []Webhook
+			var items []T
 			if err := json.Unmarshal(itemsRaw, &items); err == nil {
 				return items, nil
 			}
 		}
 	}
-	var plain []Webhook
+	var plain []T
 	if err := json.Unmarshal(raw, &plain); err == nil {
 		return plain, nil
 	}
 	// Fallback to empty slice instead of propagating an error; safer for clients.
-	return []Webhook{}, nil
+	return []T{}, nil
 }
 
 func decodeWebhook(raw json.RawMessage) (Webhook, error) {
@@ -0,0 +1,354 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// AggregateFunc is the aggregation function applied by Aggregate and AggregateOverTime.
+type AggregateFunc string
+
+// Aggregation functions supported by Port.
+const (
+	FuncCount       AggregateFunc = "count"
+	FuncAverage     AggregateFunc = "average"
+	FuncSum         AggregateFunc = "sum"
+	FuncMin         AggregateFunc = "min"
+	FuncMax         AggregateFunc = "max"
+	FuncMedian      AggregateFunc = "median"
+	FuncLast        AggregateFunc = "last"
+	FuncCountValues AggregateFunc = "countValues"
+)
+
+// AverageOf is the time unit used by the average function.
+type AverageOf string
+
+// Average units supported by Port.
+const (
+	AverageOfHour  AverageOf = "hour"
+	AverageOfDay   AverageOf = "day"
+	AverageOfWeek  AverageOf = "week"
+	AverageOfMonth AverageOf = "month"
+	AverageOfTotal AverageOf = "total"
+)
+
+// Query is a Port search query: a combinator over rules and nested queries.
+// It can be passed anywhere a map[string]any query is expected via Map.
+type Query struct {
+	Combinator string `json:"combinator"`
+	Rules      []any  `json:"rules"`
+}
+
+// And combines rules (Rule values or nested Query values) with "and".
+func And(rules ...any) Query {
+	return Query{Combinator: "and", Rules: nonNilRules(rules)}
+}
+
+// Or combines rules (Rule values or nested Query values) with "or".
+func Or(rules ...any) Query {
+	return Query{Combinator: "or", Rules: nonNilRules(rules)}
+}
+
+// Rule builds a single property rule, for example Rule("$blueprint", "=", "service").
+func Rule(property, operator string, value any) map[string]any {
+	rule := map[string]any{"property": property, "operator": operator}
+	if value != nil {
+		rule["value"] = value
+	}
+	return rule
+}
+
+// Map converts the query into the generic form used by SearchOptions and
+// the request structs.
+func (q Query) Map() map[string]any {
+	rules := make([]any, len(q.Rules))
+	for i, r := range q.Rules {
+		if nested, ok := r.(Query); ok {
+			rules[i] = nested.Map()
+			continue
+		}
+		rules[i] = r
+	}
+	combinator := q.Combinator
+	if combinator == "" {
+		combinator = "and"
+	}
+	return map[string]any{"combinator": combinator, "rules": rules}
+}
+
+func nonNilRules(rules []any) []any {
+	out := make([]any, 0, len(rules))
+	for _, r := range rules {
+		if r != nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// CountEntities builds an aggregate request counting the entities matching q.
+func CountEntities(q Query) AggregateRequest {
+	return AggregateRequest{"func": string(FuncCount), "query": q.Map()}
+}
+
+// AverageEntities builds an aggregate request averaging the number of
+// matching entities per unit of time, measured by the measureTimeBy property.
+func AverageEntities(q Query, of AverageOf, measureTimeBy string) AggregateRequest {
+	req := AggregateRequest{"func": string(FuncAverage), "query": q.Map(), "averageOf": string(of)}
+	if measureTimeBy != "" {
+		req["measureTimeBy"] = measureTimeBy
+	}
+	return req
+}
+
+// AggregateByProperty builds an aggregate request applying fn (sum, average,
+// min, max, median or last) to a property.
+func AggregateByProperty(fn AggregateFunc, property string, q Query) AggregateRequest {
+	return AggregateRequest{"func": string(fn), "property": property, "query": q.Map()}
+}
+
+// GroupByProperty counts matching entities per value of property.
+func GroupByProperty(property string, q Query) AggregateRequest {
+	return AggregateRequest{"func": string(FuncCountValues), "property": property, "query": q.Map()}
+}
+
+// GroupByRelation counts matching entities per related entity.
+func GroupByRelation(relation string, q Query) AggregateRequest {
+	return AggregateRequest{"func": string(FuncCountValues), "relation": relation, "query": q.Map()}
+}
+
+// GroupByScorecard counts matching entities per scorecard level. When rule is
+// non-empty, entities are grouped by the result of that scorecard rule.
+func GroupByScorecard(scorecard, rule string, q Query) AggregateRequest {
+	req := AggregateRequest{"func": string(FuncCountValues), "scorecard": scorecard, "query": q.Map()}
+	if rule != "" {
+		req["rule"] = rule
+	}
+	return req
+}
+
+// Validate checks the request against the payload shapes accepted by Port.
+// Requests assembled by hand are only checked for the required fields.
+func (r AggregateRequest) Validate() error {
+	if len(r) == 0 {
+		return fmt.Errorf("entities: aggregate request cannot be empty")
+	}
+	fn, _ := r["func"].(string)
+	if fn == "" {
+		return fmt.Errorf("entities: aggregate request requires func")
+	}
+	if _, ok := r["query"]; !ok {
+		return fmt.Errorf("entities: aggregate request requires query")
+	}
+	prop, _ := r["property"].(string)
+	switch AggregateFunc(fn) {
+	case FuncSum, FuncMin, FuncMax, FuncMedian, FuncLast:
+		if prop == "" {
+			return fmt.Errorf("entities: aggregate func %q requires a property", fn)
+		}
+	case FuncCountValues:
+		_, hasRel := r["relation"]
+		_, hasScorecard := r["scorecard"]
+		if prop == "" && !hasRel && !hasScorecard {
+			return fmt.Errorf("entities: countValues requires a property, relation or scorecard")
+		}
+	case FuncCount, FuncAverage:
+	default:
+		return fmt.Errorf("entities: unsupported aggregate func %q", fn)
+	}
+	return nil
+}
+
+// Bucket is a single group of a countValues aggregation.
+type Bucket struct {
+	Key   string
+	Value float64
+}
+
+// Value decodes a scalar aggregation result (count, sum, average, ...).
+func (r AggregateResponse) Value() (float64, error) {
+	if len(r.Result) == 0 {
+		return 0, fmt.Errorf("entities: aggregate response has no result")
+	}
+	var n float64
+	if err := json.Unmarshal(r.Result, &n); err == nil {
+		return n, nil
+	}
+	var wrapped struct {
+		Value *float64 `json:"value"`
+	}
+	if err := json.Unmarshal(r.Result, &wrapped); err == nil && wrapped.Value != nil {
+		return *wrapped.Value, nil
+	}
+	return 0, fmt.Errorf("entities: aggregate result is not a number: %s", r.Result)
+}
+
+// Buckets decodes a grouped (countValues) aggregation result, sorted by
+// descending value and then key. Both object ({"prod": 3}) and array
+// ([{"key": "prod", "value": 3}]) encodings are accepted.
+func (r AggregateResponse) Buckets() ([]Bucket, error) {
+	if len(r.Result) == 0 {
+		return nil, fmt.Errorf("entities: aggregate response has no result")
+	}
+	var buckets []Bucket
+	var byKey map[string]float64
+	if err := json.Unmarshal(r.Result, &byKey); err == nil {
+		for k, v := range byKey {
+			buckets = append(buckets, Bucket{Key: k, Value: v})
+		}
+	} else {
+		var rows []map[string]any
+		if err := json.Unmarshal(r.Result, &rows); err != nil {
+			return nil, fmt.Errorf("entities: aggregate result is not grouped: %s", r.Result)
+		}
+		for _, row := range rows {
+			b, ok := bucketFromRow(row)
+			if !ok {
+				return nil, fmt.Errorf("entities: unrecognized aggregate bucket %v", row)
+			}
+			buckets = append(buckets, b)
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Value != buckets[j].Value {
+			return buckets[i].Value > buckets[j].Value
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets, nil
+}
+
+func bucketFromRow(row map[string]any) (Bucket, bool) {
+	for _, k := range []string{"key", "group", "name"} {
+		raw, ok := row[k]
+		if !ok {
+			continue
+		}
+		for _, vk := range []string{"count", "value", "result"} {
+			if f, ok := toFloat(row[vk]); ok {
+				return Bucket{Key: fmt.Sprint(raw), Value: f}, true
+			}
+		}
+		return Bucket{}, false
+	}
+	// {"prod": 3} style rows with a single entry.
+	if len(row) == 1 {
+		for k, v := range row {
+			f, ok := toFloat(v)
+			return Bucket{Key: k, Value: f}, ok
+		}
+	}
+	return Bucket{}, false
+}
+
+// Time range presets accepted by AggregateOverTime and PropertiesHistory.
+const (
+	PresetToday        = "today"
+	PresetYesterday    = "yesterday"
+	PresetLastDay      = "lastDay"
+	PresetLastWeek     = "lastWeek"
+	PresetLast2Weeks   = "last2Weeks"
+	PresetLastMonth    = "lastMonth"
+	PresetLast3Months  = "last3Months"
+	PresetLast6Months  = "last6Months"
+	PresetLast12Months = "last12Months"
+)
+
+// Time intervals accepted by AggregateOverTime and PropertiesHistory.
+const (
+	IntervalHour    = "hour"
+	IntervalDay     = "day"
+	IntervalISOWeek = "isoWeek"
+	IntervalMonth   = "month"
+)
+
+// PresetRange returns a time range using one of the Preset constants.
+func PresetRange(preset, timeZone string) AggregateTimeRange {
+	return AggregateTimeRange{Preset: preset, TimeZone: timeZone}
+}
+
+// AbsoluteRange returns a custom time range between from and to.
+func AbsoluteRange(from, to time.Time, timeZone string) AggregateTimeRange {
+	return AggregateTimeRange{From: from, To: to, TimeZone: timeZone}
+}
+
+// IsAbsolute reports whether the range uses From/To instead of a preset.
+func (r AggregateTimeRange) IsAbsolute() bool {
+	return !r.From.IsZero() || !r.To.IsZero()
+}
+
+// Validate ensures either a preset or a complete, ordered absolute range is set.
+func (r AggregateTimeRange) Validate() error {
+	if !r.IsAbsolute() {
+		if r.Preset == "" {
+			return fmt.Errorf("entities: time range requires a preset or from/to")
+		}
+		return nil
+	}
+	if r.Preset != "" {
+		return fmt.Errorf("entities: time range cannot combine preset %q with from/to", r.Preset)
+	}
+	if r.From.IsZero() || r.To.IsZero() {
+		return fmt.Errorf("entities: absolute time range requires both from and to")
+	}
+	if !r.From.Before(r.To) {
+		return fmt.Errorf("entities: time range from (%s) must be before to (%s)", r.From.Format(time.RFC3339), r.To.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// MarshalJSON encodes either {"preset": ...} or {"from": ..., "to": ...}.
+func (r AggregateTimeRange) MarshalJSON() ([]byte, error) {
+	out := map[string]any{}
+	if r.IsAbsolute() {
+		out["from"] = r.From.UTC().Format(time.RFC3339)
+		out["to"] = r.To.UTC().Format(time.RFC3339)
+	} else {
+		out["preset"] = r.Preset
+	}
+	if r.TimeZone != "" {
+		out["timeZone"] = r.TimeZone
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON accepts both preset and absolute encodings.
+func (r *AggregateTimeRange) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Preset   string `json:"preset"`
+		TimeZone string `json:"timeZone"`
+		From     string `json:"from"`
+		To       string `json:"to"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = AggregateTimeRange{Preset: raw.Preset, TimeZone: raw.TimeZone}
+	for _, pair := range []struct {
+		src string
+		dst *time.Time
+	}{{raw.From, &r.From}, {raw.To, &r.To}} {
+		if pair.src == "" {
+			continue
+		}
+		t, err := parseRangeTime(pair.src)
+		if err != nil {
+			return err
+		}
+		*pair.dst = t
+	}
+	return nil
+}
+
+func parseRangeTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("entities: invalid time range bound %q", s)
+}
@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAggregateBuilders(t *testing.T) {
+	q := And(Rule("$blueprint", "=", "service"), Or(Rule("tier", "=", "gold"), Rule("tier", "=", "silver")))
+	req := GroupByProperty("lifecycle", q)
+	raw, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"func":"countValues","property":"lifecycle","query":{"combinator":"and","rules":[{"operator":"=","property":"$blueprint","value":"service"},{"combinator":"or","rules":[{"operator":"=","property":"tier","value":"gold"},{"operator":"=","property":"tier","value":"silver"}]}]}}`
+	if string(raw) != want {
+		t.Fatalf("payload mismatch\n got %s\nwant %s", raw, want)
+	}
+
+	if err := AggregateByProperty(FuncSum, "", q).Validate(); err == nil {
+		t.Fatalf("expected missing property error")
+	}
+	if err := (AggregateRequest{"func": "countValues", "query": q.Map()}).Validate(); err == nil {
+		t.Fatalf("expected missing group error")
+	}
+	if err := AverageEntities(q, AverageOfWeek, "$createdAt").Validate(); err != nil {
+		t.Fatalf("average: %v", err)
+	}
+	if err := AggregateByProperty(FuncLast, "version", q).Validate(); err != nil {
+		t.Fatalf("last: %v", err)
+	}
+	if err := AggregateByProperty(FuncLast, "", q).Validate(); err == nil {
+		t.Fatalf("expected missing property error for last")
+	}
+	stub := &stubDoer{}
+	if _, err := New(stub).Aggregate(context.Background(), AggregateByProperty(FuncLast, "version", q)); err != nil || stub.method != "POST" {
+		t.Fatalf("last should be sent: %v %q", err, stub.method)
+	}
+	stub = &stubDoer{}
+	if _, err := New(stub).Aggregate(context.Background(), AggregateRequest{"func": "bogus", "query": q.Map()}); err == nil || stub.method != "" {
+		t.Fatalf("invalid request should not be sent: %v", err)
+	}
+}
+
+func TestAggregateResultDecoding(t *testing.T) {
+	var resp AggregateResponse
+	if err := json.Unmarshal([]byte(`{"ok":true,"result":42}`), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if v, err := resp.Value(); err != nil || v != 42 {
+		t.Fatalf("Value: %v %v", v, err)
+	}
+	resp.Result = json.RawMessage(`{"prod":3,"dev":5}`)
+	buckets, err := resp.Buckets()
+	if err != nil || len(buckets) != 2 || buckets[0] != (Bucket{Key: "dev", Value: 5}) {
+		t.Fatalf("Buckets object: %v %v", buckets, err)
+	}
+	resp.Result = json.RawMessage(`[{"key":"a","count":1},{"key":"b","count":2}]`)
+	buckets, err = resp.Buckets()
+	if err != nil || len(buckets) != 2 || buckets[0].Key != "b" {
+		t.Fatalf("Buckets array: %v %v", buckets, err)
+	}
+	if _, err := resp.Value(); err == nil {
+		t.Fatalf("grouped result should not decode as value")
+	}
+}
+
+func TestAggregateTimeRange(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := AbsoluteRange(from, from.Add(48*time.Hour), "Europe/London")
+	raw, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(raw) != `{"from":"2025-01-01T00:00:00Z","timeZone":"Europe/London","to":"2025-01-03T00:00:00Z"}` {
+		t.Fatalf("unexpected encoding %s", raw)
+	}
+	var back AggregateTimeRange
+	if err := json.Unmarshal(raw, &back); err != nil || !back.From.Equal(r.From) || !back.To.Equal(r.To) {
+		t.Fatalf("round trip: %+v %v", back, err)
+	}
+	raw, _ = json.Marshal(PresetRange(PresetLastWeek, ""))
+	if string(raw) != `{"preset":"lastWeek"}` {
+		t.Fatalf("unexpected preset encoding %s", raw)
+	}
+	if err := AbsoluteRange(from, from, "").Validate(); err == nil {
+		t.Fatalf("expected empty range error")
+	}
+	stub := &stubDoer{}
+	_, err = New(stub).AggregateOverTime(context.Background(), AggregateOverTimeRequest{Blueprint: "bp"})
+	if err == nil || stub.method != "" {
+		t.Fatalf("missing time range should fail before sending: %v", err)
+	}
+}
@@ -3,12 +3,16 @@
 package entities
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
 	"github.com/port-experimental/port-go-sdk/pkg/porter"
 )
 
@@ -28,15 +32,106 @@ type Doer interface {
 }
 
 // Entity represents a Port entity.
+// CreatedAt, CreatedBy, UpdatedAt and UpdatedBy are populated by the API and
+// ignored on writes. Use the typed accessors (String, Number, Path, ...) to
+// read properties instead of type-asserting Properties values.
 type Entity struct {
 	Identifier string                 `json:"identifier"`
 	Blueprint  string                 `json:"blueprint"`
 	Title      string                 `json:"title,omitempty"`
 	Icon       string                 `json:"icon,omitempty"`
-	Team       string                 `json:"team,omitempty"`
+	Team       Teams                  `json:"team,omitempty"`
 	Properties map[string]any         `json:"properties,omitempty"`
 	Relations  map[string][]string    `json:"relations,omitempty"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt  time.Time              `json:"createdAt,omitempty"`
+	CreatedBy  string                 `json:"createdBy,omitempty"`
+	UpdatedAt  time.Time              `json:"updatedAt,omitempty"`
+	UpdatedBy  string                 `json:"updatedBy,omitempty"`
+}
+
+// MarshalJSON omits CreatedAt and UpdatedAt when they are zero, which the
+// omitempty tag cannot do for time.Time.
+func (e Entity) MarshalJSON() ([]byte, error) {
+	type plain Entity
+	out := struct {
+		plain
+		CreatedAt *time.Time `json:"createdAt,omitempty"`
+		UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	}{plain: plain(e)}
+	if !e.CreatedAt.IsZero() {
+		out.CreatedAt = &e.CreatedAt
+	}
+	if !e.UpdatedAt.IsZero() {
+		out.UpdatedAt = &e.UpdatedAt
+	}
+	return json.Marshal(out)
+}
+
+// Teams lists the teams owning an entity. Port accepts either a single team
+// name or an array, so a single team is encoded as a plain string and both
+// forms are accepted when decoding.
+type Teams []string
+
+// MarshalJSON encodes one team as a string and several as an array.
+func (t Teams) MarshalJSON() ([]byte, error) {
+	switch len(t) {
+	case 0:
+		return []byte("null"), nil
+	case 1:
+		return json.Marshal(t[0])
+	default:
+		return json.Marshal([]string(t))
+	}
+}
+
+// UnmarshalJSON accepts null, a team name or an array of team names.
+func (t *Teams) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single == "" {
+			*t = nil
+		} else {
+			*t = Teams{single}
+		}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("entities: team must be a string or array of strings: %w", err)
+	}
+	*t = many
+	return nil
+}
+
+// SingleTeam returns Teams holding name, or nil if name is empty. It eases
+// migrating code that assigned Entity.Team a string:
+//
+//	ent.Team = entities.SingleTeam("platform")
+func SingleTeam(name string) Teams {
+	if name == "" {
+		return nil
+	}
+	return Teams{name}
+}
+
+// First returns the first team, or "" if there is none. It eases migrating
+// code that read Entity.Team as a string.
+func (t Teams) First() string {
+	if len(t) == 0 {
+		return ""
+	}
+	return t[0]
+}
+
+// Contains reports whether name is one of the teams.
+func (t Teams) Contains(name string) bool {
+	for _, team := range t {
+		if team == name {
+			return true
+		}
+	}
+	return false
 }
 
 // ListOptions control pagination/filtering.
@@ -113,25 +208,45 @@ func (r ListResponse) HasMore() bool {
 // The context controls the request lifetime. Recommended timeout: 30 seconds.
 // Returns an error if the entity already exists (use Upsert for idempotent operations).
 func (s *Service) Create(ctx context.Context, blueprint string, ent Entity) error {
-	path := fmt.Sprintf("/v1/blueprints/%s/entities", url.PathEscape(blueprint))
-	return s.doer.Do(ctx, "POST", path, entityPayload(ent), nil)
+	return s.Write(ctx, blueprint, ent, nil)
 }
 
 // Upsert creates or updates an entity (idempotent operation).
 // The context controls the request lifetime. Recommended timeout: 30 seconds.
 // This method merges properties with existing entities if they already exist.
+// Use Write with WriteReplace to overwrite the entity instead.
 func (s *Service) Upsert(ctx context.Context, blueprint string, ent Entity) error {
-	path := fmt.Sprintf("/v1/blueprints/%s/entities?upsert=true&merge=true", url.PathEscape(blueprint))
-	return s.doer.Do(ctx, "POST", path, entityPayload(ent), nil)
+	return s.Write(ctx, blueprint, ent, &WriteOptions{Upsert: true, Mode: WriteMerge})
 }
 
 // Get fetches an entity by identifier.
 // The context controls the request lifetime. Recommended timeout: 30 seconds.
 func (s *Service) Get(ctx context.Context, blueprint, identifier string) (Entity, error) {
-	var out Entity
+	var resp entityResponse
 	path := fmt.Sprintf("/v1/blueprints/%s/entities/%s", url.PathEscape(blueprint), url.PathEscape(identifier))
-	err := s.doer.Do(ctx, "GET", path, nil, &out)
-	return out, err
+	if err := s.doer.Do(ctx, "GET", path, nil, &resp); err != nil {
+		return Entity{}, err
+	}
+	return resp.Entity, nil
+}
+
+// entityResponse decodes a single-entity response. Port wraps the entity as
+// {"ok":true,"entity":{...}}; a bare entity is accepted as well.
+type entityResponse struct {
+	Entity Entity
+}
+
+func (r *entityResponse) UnmarshalJSON(data []byte) error {
+	var env struct {
+		Entity json.RawMessage `json:"entity"`
+	}
+	if err := json.Unmarshal(data, &env); err != nil {
+		return err
+	}
+	if len(env.Entity) > 0 && string(env.Entity) != "null" {
+		return json.Unmarshal(env.Entity, &r.Entity)
+	}
+	return json.Unmarshal(data, &r.Entity)
 }
 
 // Delete removes an entity.
@@ -164,6 +279,7 @@ func (s *Service) List(ctx context.Context, blueprint string, opts *ListOptions)
 // Update applies a partial update to entity properties (merge=true).
 // The context controls the request lifetime. Recommended timeout: 30 seconds.
 // This method merges the provided properties with existing entity properties.
+// Set a property to Unset to remove it; use Patch to also change relations.
 func (s *Service) Update(ctx context.Context, blueprint, identifier string, properties map[string]any) error {
 	path := fmt.Sprintf("/v1/blueprints/%s/entities/%s", url.PathEscape(blueprint), url.PathEscape(identifier))
 	payload := map[string]any{
@@ -355,6 +471,110 @@ func (s *Service) ListAllBlueprint(ctx context.Context, blueprint string, opts S
 	return allEntities, nil
 }
 
+// ForEach streams every entity matching the search criteria to fn, page by
+// page. Entities are decoded one at a time as the response arrives, so pages
+// need not fit in memory and are not subject to the client's response limit.
+// It stops at the first error returned by fn.
+//
+// Example:
+//
+//	err := svc.ForEach(ctx, entities.SearchOptions{Limit: 1000}, func(e entities.Entity) error {
+//		return exporter.Write(e)
+//	})
+func (s *Service) ForEach(ctx context.Context, opts SearchOptions, fn func(Entity) error) error {
+	return s.forEach(ctx, "/v1/entities/search", opts, fn)
+}
+
+// ForEachBlueprint is ForEach restricted to one blueprint.
+func (s *Service) ForEachBlueprint(ctx context.Context, blueprint string, opts SearchOptions, fn func(Entity) error) error {
+	path := fmt.Sprintf("/v1/blueprints/%s/entities/search", url.PathEscape(blueprint))
+	return s.forEach(ctx, path, opts, fn)
+}
+
+func (s *Service) forEach(ctx context.Context, path string, opts SearchOptions, fn func(Entity) error) error {
+	for {
+		page := &entityStream{fn: fn}
+		if err := s.doer.Do(httpx.ContextWithIdempotent(ctx), "POST", path, searchBody(opts), page); err != nil {
+			return err
+		}
+		if page.Next == "" {
+			return nil
+		}
+		opts.From = page.Next
+	}
+}
+
+// entityStream decodes a search response, handing each entity to fn instead
+// of collecting them. It implements the client's StreamDecoder and falls
+// back to json.Unmarshaler for doers that buffer the body.
+type entityStream struct {
+	fn   func(Entity) error
+	Next string
+}
+
+func (p *entityStream) DecodeStream(dec *json.Decoder) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "entities":
+			if err := p.decodeEntities(dec); err != nil {
+				return err
+			}
+		case "next":
+			var next *string
+			if err := dec.Decode(&next); err != nil {
+				return err
+			}
+			if next != nil {
+				p.Next = *next
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func (p *entityStream) decodeEntities(dec *json.Decoder) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		var ent Entity
+		if err := dec.Decode(&ent); err != nil {
+			return err
+		}
+		if err := p.fn(ent); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func (p *entityStream) UnmarshalJSON(data []byte) error {
+	return p.DecodeStream(json.NewDecoder(bytes.NewReader(data)))
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("entities: unexpected %v in search response, want %v", tok, want)
+	}
+	return nil
+}
+
 // SearchBlueprint searches within a given blueprint.
 // The context controls the request lifetime. Recommended timeout: 30 seconds.
 //
@@ -366,6 +586,12 @@ func (s *Service) SearchBlueprint(ctx context.Context, blueprint string, opts Se
 }
 
 func (s *Service) search(ctx context.Context, path string, opts SearchOptions) (ListResponse, error) {
+	var out ListResponse
+	err := s.doer.Do(httpx.ContextWithIdempotent(ctx), "POST", path, searchBody(opts), &out)
+	return out, err
+}
+
+func searchBody(opts SearchOptions) map[string]any {
 	body := map[string]any{}
 	if len(opts.Include) > 0 {
 		body["include"] = opts.Include
@@ -383,11 +609,9 @@ func (s *Service) search(ctx context.Context, path string, opts SearchOptions) (
 		body["limit"] = opts.Limit
 	}
 	if len(body) == 0 {
-		body = nil
+		return nil
 	}
-	var out ListResponse
-	err := s.doer.Do(ctx, "POST", path, body, &out)
-	return out, err
+	return body
 }
 
 func entityPayload(ent Entity) map[string]any {
@@ -401,7 +625,7 @@ func entityPayload(ent Entity) map[string]any {
 	if ent.Icon != "" {
 		payload["icon"] = ent.Icon
 	}
-	if ent.Team != "" {
+	if len(ent.Team) > 0 {
 		payload["team"] = ent.Team
 	}
 	if rel := cloneRelations(ent.Relations); rel != nil {
@@ -423,24 +647,27 @@ func cloneRelations(in map[string][]string) map[string]any {
 	return rel
 }
 
-// AggregateRequest mirrors the flexible aggregate payload.
+// AggregateRequest mirrors the flexible aggregate payload. Prefer the typed
+// constructors (CountEntities, AggregateByProperty, GroupByProperty, ...).
 type AggregateRequest map[string]any
 
-// AggregateResponse represents /v1/entities/aggregate output.
+// AggregateResponse represents /v1/entities/aggregate output. Use Value or
+// Buckets to decode Result.
 type AggregateResponse struct {
-	OK                 bool     `json:"ok"`
-	Entities           []Entity `json:"entities"`
-	MatchingBlueprints []string `json:"matchingBlueprints,omitempty"`
-	FailedBlueprints   []string `json:"failedBlueprints,omitempty"`
+	OK                 bool            `json:"ok"`
+	Result             json.RawMessage `json:"result,omitempty"`
+	Entities           []Entity        `json:"entities"`
+	MatchingBlueprints []string        `json:"matchingBlueprints,omitempty"`
+	FailedBlueprints   []string        `json:"failedBlueprints,omitempty"`
 }
 
 // Aggregate executes /v1/entities/aggregate with the provided payload.
 func (s *Service) Aggregate(ctx context.Context, req AggregateRequest) (AggregateResponse, error) {
-	if len(req) == 0 {
-		return AggregateResponse{}, fmt.Errorf("entities: aggregate request cannot be empty")
+	if err := req.Validate(); err != nil {
+		return AggregateResponse{}, err
 	}
 	var resp AggregateResponse
-	err := s.doer.Do(ctx, "POST", "/v1/entities/aggregate", req, &resp)
+	err := s.doer.Do(httpx.ContextWithIdempotent(ctx), "POST", "/v1/entities/aggregate", req, &resp)
 	return resp, err
 }
 
@@ -457,10 +684,13 @@ type AggregateOverTimeRequest struct {
 	BreakdownProperty string             `json:"breakdownProperty,omitempty"`
 }
 
-// AggregateTimeRange defines preset + timezone.
+// AggregateTimeRange defines either a preset or an absolute From/To range,
+// plus an optional timezone. See PresetRange and AbsoluteRange.
 type AggregateTimeRange struct {
-	Preset   string `json:"preset"`
-	TimeZone string `json:"timeZone,omitempty"`
+	Preset   string    `json:"preset,omitempty"`
+	From     time.Time `json:"from,omitempty"`
+	To       time.Time `json:"to,omitempty"`
+	TimeZone string    `json:"timeZone,omitempty"`
 }
 
 // AggregateOverTimeResponse captures the returned series.
@@ -478,8 +708,11 @@ type AggregateOverTimeResult struct {
 
 // AggregateOverTime calls /v1/entities/aggregate-over-time.
 func (s *Service) AggregateOverTime(ctx context.Context, req AggregateOverTimeRequest) (AggregateOverTimeResponse, error) {
+	if err := req.TimeRange.Validate(); err != nil {
+		return AggregateOverTimeResponse{}, err
+	}
 	var resp AggregateOverTimeResponse
-	err := s.doer.Do(ctx, "POST", "/v1/entities/aggregate-over-time", req, &resp)
+	err := s.doer.Do(httpx.ContextWithIdempotent(ctx), "POST", "/v1/entities/aggregate-over-time", req, &resp)
 	return resp, err
 }
 
@@ -514,6 +747,6 @@ type PropertiesHistoryResult struct {
 // PropertiesHistory hits /v1/entities/properties-history.
 func (s *Service) PropertiesHistory(ctx context.Context, req PropertiesHistoryRequest) (PropertiesHistoryResponse, error) {
 	var resp PropertiesHistoryResponse
-	err := s.doer.Do(ctx, "POST", "/v1/entities/properties-history", req, &resp)
+	err := s.doer.Do(httpx.ContextWithIdempotent(ctx), "POST", "/v1/entities/properties-history", req, &resp)
 	return resp, err
 }
@@ -2,18 +2,23 @@ package entities
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 	"reflect"
 	"testing"
+
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
 )
 
 type stubDoer struct {
+	ctx    context.Context
 	method string
 	path   string
 	body   any
 }
 
 func (s *stubDoer) Do(ctx context.Context, method, path string, body any, out any) error {
+	s.ctx = ctx
 	s.method = method
 	s.path = path
 	s.body = body
@@ -275,3 +280,29 @@ func TestPropertiesHistory(t *testing.T) {
 		t.Fatalf("request mismatch %#v", stub.body)
 	}
 }
+
+func TestReadOnlyPostsAreRetryable(t *testing.T) {
+	stub := &stubDoer{}
+	svc := New(stub)
+	ctx := context.Background()
+	idempotent := func() bool {
+		req, _ := http.NewRequestWithContext(stub.ctx, stub.method, "http://port"+stub.path, http.NoBody)
+		return httpx.IsIdempotent(req)
+	}
+	reads := map[string]func() error{
+		"search": func() error { _, err := svc.Search(ctx, SearchOptions{}); return err },
+		"aggregate": func() error {
+			_, err := svc.Aggregate(ctx, AggregateRequest{"func": "count", "query": map[string]any{"combinator": "and", "rules": []any{}}})
+			return err
+		},
+		"properties history": func() error { _, err := svc.PropertiesHistory(ctx, PropertiesHistoryRequest{}); return err },
+	}
+	for name, read := range reads {
+		if err := read(); err != nil || stub.method != "POST" || !idempotent() {
+			t.Errorf("%s: POST %s not marked idempotent (err %v)", name, stub.path, err)
+		}
+	}
+	if err := svc.Upsert(ctx, "service", Entity{Identifier: "a"}); err != nil || idempotent() {
+		t.Fatalf("upsert marked idempotent (err %v)", err)
+	}
+}
@@ -2,6 +2,9 @@ package entities
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -107,3 +110,46 @@ func (m *paginationMockDoer) Do(ctx context.Context, method, path string, body a
 	// Should not reach here
 	return nil
 }
+
+// jsonPagesDoer serves search pages as JSON, as a buffering client would.
+type jsonPagesDoer struct {
+	pages []string
+	froms []any
+}
+
+func (d *jsonPagesDoer) Do(ctx context.Context, method, path string, body any, out any) error {
+	d.froms = append(d.froms, body.(map[string]any)["from"])
+	page := d.pages[0]
+	d.pages = d.pages[1:]
+	return json.Unmarshal([]byte(page), out)
+}
+
+func TestForEachBlueprint(t *testing.T) {
+	doer := &jsonPagesDoer{pages: []string{
+		`{"ok":true,"next":"p2","entities":[{"identifier":"a","properties":{"n":1}},{"identifier":"b"}]}`,
+		`{"entities":[{"identifier":"c"}],"next":null,"ok":true}`,
+	}}
+	svc := New(doer)
+	var ids []string
+	err := svc.ForEachBlueprint(context.Background(), "service", SearchOptions{Limit: 2}, func(e Entity) error {
+		ids = append(ids, e.Identifier)
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Fatalf("ids %v, err %v", ids, err)
+	}
+	if !reflect.DeepEqual(doer.froms, []any{nil, "p2"}) {
+		t.Fatalf("pagination tokens %v", doer.froms)
+	}
+
+	stop := errors.New("stop")
+	doer.pages = []string{`{"entities":[{"identifier":"a"},{"identifier":"b"}],"next":"p2"}`}
+	calls := 0
+	err = svc.ForEachBlueprint(context.Background(), "service", SearchOptions{}, func(Entity) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected to stop after first entity, got %v after %d calls", err, calls)
+	}
+}
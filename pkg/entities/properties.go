@@ -0,0 +1,205 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPropertyNotFound is returned when a property (or JSON pointer
+	// segment) is missing or null.
+	ErrPropertyNotFound = errors.New("entities: property not found")
+	// ErrPropertyType is returned when a property has an unexpected type.
+	ErrPropertyType = errors.New("entities: property has unexpected type")
+)
+
+// PropertyError describes a failed typed property lookup. It matches
+// ErrPropertyNotFound or ErrPropertyType with errors.Is.
+type PropertyError struct {
+	Entity   string
+	Property string
+	Want     string
+	Got      any
+	Err      error
+}
+
+func (e *PropertyError) Error() string {
+	if errors.Is(e.Err, ErrPropertyNotFound) {
+		return fmt.Sprintf("entities: %s: property %q not found", e.Entity, e.Property)
+	}
+	return fmt.Sprintf("entities: %s: property %q is %T, want %s", e.Entity, e.Property, e.Got, e.Want)
+}
+
+// Unwrap returns ErrPropertyNotFound or ErrPropertyType.
+func (e *PropertyError) Unwrap() error {
+	return e.Err
+}
+
+func (e Entity) lookup(name, want string) (any, error) {
+	v, ok := e.Properties[name]
+	if !ok || v == nil {
+		return nil, &PropertyError{Entity: e.Identifier, Property: name, Want: want, Err: ErrPropertyNotFound}
+	}
+	return v, nil
+}
+
+func (e Entity) typeError(name, want string, got any) error {
+	return &PropertyError{Entity: e.Identifier, Property: name, Want: want, Got: got, Err: ErrPropertyType}
+}
+
+// String returns a string property.
+func (e Entity) String(name string) (string, error) {
+	v, err := e.lookup(name, "string")
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", e.typeError(name, "string", v)
+	}
+	return s, nil
+}
+
+// Number returns a numeric property as float64. JSON numbers, Go integer
+// and float types and json.Number are accepted.
+func (e Entity) Number(name string) (float64, error) {
+	v, err := e.lookup(name, "number")
+	if err != nil {
+		return 0, err
+	}
+	f, ok := toFloat(v)
+	if !ok {
+		return 0, e.typeError(name, "number", v)
+	}
+	return f, nil
+}
+
+// Bool returns a boolean property.
+func (e Entity) Bool(name string) (bool, error) {
+	v, err := e.lookup(name, "bool")
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, e.typeError(name, "bool", v)
+	}
+	return b, nil
+}
+
+// Time parses a date-time property. RFC 3339 strings (with or without
+// fractional seconds), plain dates (2006-01-02) and time.Time values are accepted.
+func (e Entity) Time(name string) (time.Time, error) {
+	v, err := e.lookup(name, "date-time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch val := v.(type) {
+	case time.Time:
+		return val, nil
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, perr := time.Parse(layout, val); perr == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, e.typeError(name, "date-time", v)
+}
+
+// StringSlice returns an array property whose items are all strings.
+func (e Entity) StringSlice(name string) ([]string, error) {
+	v, err := e.lookup(name, "[]string")
+	if err != nil {
+		return nil, err
+	}
+	switch val := v.(type) {
+	case []string:
+		return append([]string(nil), val...), nil
+	case []any:
+		out := make([]string, len(val))
+		for i, item := range val {
+			s, ok := item.(string)
+			if !ok {
+				return nil, e.typeError(fmt.Sprintf("%s[%d]", name, i), "string", item)
+			}
+			out[i] = s
+		}
+		return out, nil
+	}
+	return nil, e.typeError(name, "[]string", v)
+}
+
+// Object returns an object property.
+func (e Entity) Object(name string) (map[string]any, error) {
+	v, err := e.lookup(name, "object")
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, e.typeError(name, "object", v)
+	}
+	return obj, nil
+}
+
+// Path resolves an RFC 6901 JSON pointer against the entity properties, for
+// example "/config/replicas" or "/endpoints/0/url".
+func (e Entity) Path(pointer string) (any, error) {
+	if pointer == "" || pointer == "/" {
+		return e.Properties, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("entities: invalid JSON pointer %q", pointer)
+	}
+	var cur any = e.Properties
+	for _, raw := range strings.Split(pointer[1:], "/") {
+		seg := strings.ReplaceAll(strings.ReplaceAll(raw, "~1", "/"), "~0", "~")
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[seg]
+			if !ok || next == nil {
+				return nil, &PropertyError{Entity: e.Identifier, Property: pointer, Err: ErrPropertyNotFound}
+			}
+			cur = next
+		case []any:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, &PropertyError{Entity: e.Identifier, Property: pointer, Err: ErrPropertyNotFound}
+			}
+			cur = node[idx]
+		default:
+			return nil, e.typeError(pointer, "object or array", cur)
+		}
+	}
+	return cur, nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
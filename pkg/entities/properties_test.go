@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEntityDecodeMetadataAndTeams(t *testing.T) {
+	raw := `{"identifier":"api","blueprint":"service","team":["a","b"],
+		"createdAt":"2025-01-01T10:00:00.000Z","updatedBy":"bot","updatedAt":"2025-02-01T10:00:00Z"}`
+	var ent Entity
+	if err := json.Unmarshal([]byte(raw), &ent); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(ent.Team) != 2 || !ent.Team.Contains("b") {
+		t.Fatalf("teams not decoded: %v", ent.Team)
+	}
+	if ent.CreatedAt.Month() != time.January || ent.UpdatedBy != "bot" {
+		t.Fatalf("metadata not decoded: %+v", ent)
+	}
+
+	if err := json.Unmarshal([]byte(`{"team":"solo"}`), &ent); err != nil || len(ent.Team) != 1 {
+		t.Fatalf("single team: %v %v", ent.Team, err)
+	}
+	out, err := json.Marshal(Teams{"solo"})
+	if err != nil || string(out) != `"solo"` {
+		t.Fatalf("single team should encode as string: %s %v", out, err)
+	}
+	out, err = json.Marshal(Teams{"a", "b"})
+	if err != nil || string(out) != `["a","b"]` {
+		t.Fatalf("teams should encode as array: %s %v", out, err)
+	}
+	if SingleTeam("solo").First() != "solo" || SingleTeam("") != nil || Teams(nil).First() != "" {
+		t.Fatal("team migration helpers")
+	}
+}
+
+func TestEntityEncodeOmitsZeroTimes(t *testing.T) {
+	out, err := json.Marshal(Entity{Identifier: "api", Blueprint: "service"})
+	if err != nil || string(out) != `{"identifier":"api","blueprint":"service"}` {
+		t.Fatalf("zero times should be omitted: %s %v", out, err)
+	}
+	ts := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	out, err = json.Marshal(Entity{Identifier: "api", Blueprint: "service", UpdatedAt: ts})
+	if err != nil || string(out) != `{"identifier":"api","blueprint":"service","updatedAt":"2025-01-01T10:00:00Z"}` {
+		t.Fatalf("updatedAt should be encoded: %s %v", out, err)
+	}
+}
+
+func TestEntityAccessors(t *testing.T) {
+	var ent Entity
+	raw := `{"identifier":"api","properties":{
+		"name":"API","replicas":3,"public":true,"since":"2024-05-01T00:00:00Z",
+		"tags":["go","grpc"],"config":{"endpoints":[{"url":"https://x"}],"a/b":1}}}`
+	if err := json.Unmarshal([]byte(raw), &ent); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if s, err := ent.String("name"); err != nil || s != "API" {
+		t.Fatalf("String: %q %v", s, err)
+	}
+	if n, err := ent.Number("replicas"); err != nil || n != 3 {
+		t.Fatalf("Number: %v %v", n, err)
+	}
+	if b, err := ent.Bool("public"); err != nil || !b {
+		t.Fatalf("Bool: %v %v", b, err)
+	}
+	if ts, err := ent.Time("since"); err != nil || ts.Year() != 2024 {
+		t.Fatalf("Time: %v %v", ts, err)
+	}
+	if tags, err := ent.StringSlice("tags"); err != nil || len(tags) != 2 || tags[1] != "grpc" {
+		t.Fatalf("StringSlice: %v %v", tags, err)
+	}
+	if obj, err := ent.Object("config"); err != nil || obj["endpoints"] == nil {
+		t.Fatalf("Object: %v %v", obj, err)
+	}
+	if v, err := ent.Path("/config/endpoints/0/url"); err != nil || v != "https://x" {
+		t.Fatalf("Path: %v %v", v, err)
+	}
+	if v, err := ent.Path("/config/a~1b"); err != nil || v != 1.0 {
+		t.Fatalf("Path escape: %v %v", v, err)
+	}
+
+	_, err := ent.String("replicas")
+	var perr *PropertyError
+	if !errors.Is(err, ErrPropertyType) || !errors.As(err, &perr) || perr.Want != "string" {
+		t.Fatalf("expected type error, got %v", err)
+	}
+	if _, err := ent.Number("missing"); !errors.Is(err, ErrPropertyNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := ent.Path("/config/endpoints/5"); !errors.Is(err, ErrPropertyNotFound) {
+		t.Fatalf("expected not found for index, got %v", err)
+	}
+}
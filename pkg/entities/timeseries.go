@@ -0,0 +1,260 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Point is a single sample of a time series.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// GapFill selects how FillGaps populates missing buckets.
+type GapFill int
+
+const (
+	// FillZero inserts 0 for missing buckets.
+	FillZero GapFill = iota
+	// FillPrevious repeats the previous value (0 before the first sample).
+	FillPrevious
+	// FillNaN inserts math.NaN() so charts can render gaps.
+	FillNaN
+)
+
+// epochTime converts Port's epoch numbers to time. Values above 1e11 are
+// treated as milliseconds, smaller ones as seconds.
+func epochTime(v float64) time.Time {
+	if math.Abs(v) >= 1e11 {
+		return time.UnixMilli(int64(v)).UTC()
+	}
+	sec, frac := math.Modf(v)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+// MinTime returns MinDate as a time.
+func (r AggregateOverTimeResult) MinTime() time.Time { return epochTime(r.MinDate) }
+
+// MaxTime returns MaxDate as a time.
+func (r AggregateOverTimeResult) MaxTime() time.Time { return epochTime(r.MaxDate) }
+
+// Series decodes the rows into one series per column (property name or
+// breakdown value). Rows carry their bucket start in the "date" column; rows
+// without it are placed sequentially from MinDate using interval.
+func (r AggregateOverTimeResult) Series(interval string, loc *time.Location) (map[string][]Point, error) {
+	out := map[string][]Point{}
+	start := AlignTime(r.MinTime(), interval, loc)
+	for i, row := range r.Data {
+		ts := start
+		if d, ok := row["date"]; ok {
+			ts = epochTime(d)
+		} else {
+			var err error
+			if ts, err = stepTime(start, interval, i, loc); err != nil {
+				return nil, err
+			}
+		}
+		for key, v := range row {
+			if key == "date" {
+				continue
+			}
+			out[key] = append(out[key], Point{Time: ts, Value: v})
+		}
+	}
+	for key := range out {
+		sortPoints(out[key])
+	}
+	return out, nil
+}
+
+// MinTime returns MinDate as a time.
+func (r PropertiesHistoryResult) MinTime() time.Time { return epochTime(r.MinDate) }
+
+// MaxTime returns MaxDate as a time.
+func (r PropertiesHistoryResult) MaxTime() time.Time { return epochTime(r.MaxDate) }
+
+// Points places the samples on consecutive interval buckets starting at MinDate.
+func (r PropertiesHistoryResult) Points(interval string, loc *time.Location) ([]Point, error) {
+	start := AlignTime(r.MinTime(), interval, loc)
+	out := make([]Point, 0, len(r.Data))
+	for i, v := range r.Data {
+		ts, err := stepTime(start, interval, i, loc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Point{Time: ts, Value: v})
+	}
+	return out, nil
+}
+
+// AlignTime truncates t to the start of its bucket (hour, day, isoWeek or
+// month) in loc. A nil loc means UTC. Unknown intervals return t unchanged.
+func AlignTime(t time.Time, interval string, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	y, m, d := t.Date()
+	switch interval {
+	case IntervalHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+	case IntervalDay:
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	case IntervalISOWeek:
+		offset := (int(t.Weekday()) + 6) % 7 // Monday = 0
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+	case IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	default:
+		return t
+	}
+}
+
+// stepTime returns the start of the n-th bucket after start. Calendar
+// arithmetic keeps day, week and month buckets aligned across DST changes.
+func stepTime(start time.Time, interval string, n int, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	start = start.In(loc)
+	switch interval {
+	case IntervalHour:
+		return start.Add(time.Duration(n) * time.Hour), nil
+	case IntervalDay:
+		return start.AddDate(0, 0, n), nil
+	case IntervalISOWeek:
+		return start.AddDate(0, 0, 7*n), nil
+	case IntervalMonth:
+		return start.AddDate(0, n, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("entities: unsupported time interval %q", interval)
+	}
+}
+
+// FillGaps returns one point per interval bucket between from and to
+// (inclusive), aligning every sample to its bucket and filling buckets
+// without samples according to mode. Samples sharing a bucket keep the last value.
+func FillGaps(points []Point, from, to time.Time, interval string, loc *time.Location, mode GapFill) ([]Point, error) {
+	start := AlignTime(from, interval, loc)
+	end := AlignTime(to, interval, loc)
+	byBucket := make(map[int64]float64, len(points))
+	for _, p := range points {
+		byBucket[AlignTime(p.Time, interval, loc).Unix()] = p.Value
+	}
+	var out []Point
+	prev := 0.0
+	for i := 0; ; i++ {
+		ts, err := stepTime(start, interval, i, loc)
+		if err != nil {
+			return nil, err
+		}
+		if ts.After(end) {
+			break
+		}
+		v, ok := byBucket[ts.Unix()]
+		if !ok {
+			switch mode {
+			case FillPrevious:
+				v = prev
+			case FillNaN:
+				v = math.NaN()
+			default:
+				v = 0
+			}
+		}
+		prev = v
+		out = append(out, Point{Time: ts, Value: v})
+	}
+	return out, nil
+}
+
+func sortPoints(points []Point) {
+	sort.Slice(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+}
+
+// HistoryBatchOptions configure PropertiesHistoryBatch.
+type HistoryBatchOptions struct {
+	TimeInterval string
+	TimeRange    *PropertiesHistoryTimeRange
+	// Concurrency bounds parallel requests. Defaults to 4.
+	Concurrency int
+}
+
+// HistorySeries is the history of one property of one entity.
+type HistorySeries struct {
+	Entity   string
+	Property string
+	Points   []Point
+	Err      error
+}
+
+// PropertiesHistoryBatch fetches the history of every property for every
+// entity concurrently, issuing one request per entity/property pair. Results
+// are returned in input order; per-series failures are reported in
+// HistorySeries.Err and joined into the returned error.
+func (s *Service) PropertiesHistoryBatch(ctx context.Context, blueprint string, entityIDs, properties []string, opts HistoryBatchOptions) ([]HistorySeries, error) {
+	if opts.TimeInterval == "" {
+		opts.TimeInterval = IntervalDay
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	var loc *time.Location
+	if opts.TimeRange != nil && opts.TimeRange.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(opts.TimeRange.TimeZone); err != nil {
+			return nil, fmt.Errorf("entities: invalid time zone: %w", err)
+		}
+	}
+	results := make([]HistorySeries, 0, len(entityIDs)*len(properties))
+	for _, id := range entityIDs {
+		for _, prop := range properties {
+			results = append(results, HistorySeries{Entity: id, Property: prop})
+		}
+	}
+
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i := range results {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(results); j++ {
+				results[j].Err = ctx.Err()
+			}
+			wg.Wait()
+			return results, ctx.Err()
+		}
+		wg.Add(1)
+		go func(item *HistorySeries) {
+			defer func() { <-sem; wg.Done() }()
+			resp, err := s.PropertiesHistory(ctx, PropertiesHistoryRequest{
+				EntityIdentifier:    item.Entity,
+				BlueprintIdentifier: blueprint,
+				PropertyNames:       []string{item.Property},
+				TimeInterval:        opts.TimeInterval,
+				TimeRange:           opts.TimeRange,
+			})
+			if err != nil {
+				item.Err = err
+				return
+			}
+			item.Points, item.Err = resp.Result.Points(opts.TimeInterval, loc)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", r.Entity, r.Property, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package entities
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAggregateOverTimeSeries(t *testing.T) {
+	day := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	res := AggregateOverTimeResult{
+		MinDate: float64(day.UnixMilli()),
+		MaxDate: float64(day.AddDate(0, 0, 1).UnixMilli()),
+		Data: []map[string]float64{
+			{"date": float64(day.AddDate(0, 0, 1).UnixMilli()), "prod": 4, "dev": 1},
+			{"date": float64(day.UnixMilli()), "prod": 2},
+		},
+	}
+	if !res.MinTime().Equal(day) {
+		t.Fatalf("MinTime %v", res.MinTime())
+	}
+	series, err := res.Series(IntervalDay, nil)
+	if err != nil {
+		t.Fatalf("series: %v", err)
+	}
+	prod := series["prod"]
+	if len(prod) != 2 || !prod[0].Time.Equal(day) || prod[1].Value != 4 {
+		t.Fatalf("prod series %v", prod)
+	}
+	if len(series["dev"]) != 1 {
+		t.Fatalf("dev series %v", series["dev"])
+	}
+}
+
+func TestAlignTimeAndFillGaps(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tz database unavailable: %v", err)
+	}
+	// Thursday 2025-03-06 15:30 local -> ISO week starts Monday 2025-03-03.
+	ts := time.Date(2025, 3, 6, 15, 30, 0, 0, loc)
+	if got := AlignTime(ts, IntervalISOWeek, loc); !got.Equal(time.Date(2025, 3, 3, 0, 0, 0, 0, loc)) {
+		t.Fatalf("isoWeek align %v", got)
+	}
+	if got := AlignTime(ts, IntervalMonth, loc); got.Day() != 1 || got.Hour() != 0 {
+		t.Fatalf("month align %v", got)
+	}
+
+	// Daily buckets across the DST switch on 2025-03-09 stay at local midnight.
+	from := time.Date(2025, 3, 8, 0, 0, 0, 0, loc)
+	points := []Point{
+		{Time: from.Add(5 * time.Hour), Value: 1},
+		{Time: time.Date(2025, 3, 11, 12, 0, 0, 0, loc), Value: 3},
+	}
+	filled, err := FillGaps(points, from, from.AddDate(0, 0, 3), IntervalDay, loc, FillPrevious)
+	if err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	want := []float64{1, 1, 1, 3}
+	if len(filled) != len(want) {
+		t.Fatalf("expected %d points, got %v", len(want), filled)
+	}
+	for i, p := range filled {
+		if p.Value != want[i] || p.Time.In(loc).Hour() != 0 {
+			t.Fatalf("point %d = %v (%v), want %v at midnight", i, p.Value, p.Time, want[i])
+		}
+	}
+	nan, err := FillGaps(points[:1], from, from.AddDate(0, 0, 1), IntervalDay, loc, FillNaN)
+	if err != nil || !math.IsNaN(nan[1].Value) {
+		t.Fatalf("expected NaN gap, got %v %v", nan, err)
+	}
+}
+
+type historyDoer struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (d *historyDoer) Do(ctx context.Context, method, path string, body any, out any) error {
+	req := body.(PropertiesHistoryRequest)
+	d.mu.Lock()
+	d.seen[req.EntityIdentifier+"/"+req.PropertyNames[0]] = true
+	d.mu.Unlock()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	*out.(*PropertiesHistoryResponse) = PropertiesHistoryResponse{OK: true, Result: PropertiesHistoryResult{
+		MinDate: float64(start.UnixMilli()),
+		Data:    []float64{1, 2, 3},
+	}}
+	return nil
+}
+
+func TestPropertiesHistoryBatch(t *testing.T) {
+	doer := &historyDoer{seen: map[string]bool{}}
+	res, err := New(doer).PropertiesHistoryBatch(context.Background(), "svc", []string{"a", "b"}, []string{"p1", "p2"}, HistoryBatchOptions{
+		TimeInterval: IntervalDay,
+		Concurrency:  2,
+	})
+	if err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	if len(res) != 4 || len(doer.seen) != 4 {
+		t.Fatalf("expected 4 series, got %d (requests %v)", len(res), doer.seen)
+	}
+	if res[1].Entity != "a" || res[1].Property != "p2" {
+		t.Fatalf("results out of order: %+v", res[1])
+	}
+	if pts := res[3].Points; len(pts) != 3 || pts[2].Time.Day() != 3 || pts[2].Value != 3 {
+		t.Fatalf("bad points %v", pts)
+	}
+}
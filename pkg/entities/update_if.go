@@ -0,0 +1,225 @@
+package entities
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// defaultUpdateIfAttempts bounds how often UpdateIf re-runs the mutate
+// function after detecting a concurrent write.
+const defaultUpdateIfAttempts = 5
+
+// UpdateIfOptions configure UpdateIfWithOptions.
+type UpdateIfOptions struct {
+	// ExpectedUpdatedAt, when non-zero, must match the entity's updatedAt on
+	// the first read or a *ConflictError is returned immediately.
+	ExpectedUpdatedAt time.Time
+	// MaxAttempts bounds how often mutate runs when concurrent writes are
+	// detected. Defaults to 5.
+	MaxAttempts int
+}
+
+// ConflictError reports that an entity changed while UpdateIf was applying a
+// read-modify-write cycle.
+type ConflictError struct {
+	Blueprint  string
+	Identifier string
+	// Expected is the updatedAt value the caller (or the last read) expected.
+	Expected time.Time
+	// Actual is the updatedAt value observed on the server.
+	Actual   time.Time
+	Attempts int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("entities: %s/%s was modified concurrently (expected updatedAt %s, got %s) after %d attempt(s)",
+		e.Blueprint, e.Identifier, e.Expected.Format(time.RFC3339Nano), e.Actual.Format(time.RFC3339Nano), e.Attempts)
+}
+
+// UpdateIf performs an optimistic read-modify-write on an entity.
+//
+// The entity is fetched and, when expectedUpdatedAt is non-zero, its
+// updatedAt must match or a *ConflictError is returned immediately. mutate
+// receives a copy of the current entity; only the fields it changes are sent
+// with PATCH. Right before writing the entity is read again and, if it changed
+// in the meantime (by updatedAt, or by content hash when updatedAt is not
+// returned), mutate is re-run against the fresh copy, up to 5 attempts; use
+// UpdateIfWithOptions to change the limit.
+//
+// Port has no conditional write, so the check narrows but cannot fully close
+// the window between the final read and the write.
+func (s *Service) UpdateIf(ctx context.Context, blueprint, identifier string, expectedUpdatedAt time.Time, mutate func(*Entity) error) (Entity, error) {
+	return s.UpdateIfWithOptions(ctx, blueprint, identifier, UpdateIfOptions{ExpectedUpdatedAt: expectedUpdatedAt}, mutate)
+}
+
+// UpdateIfWithOptions is UpdateIf with configurable options.
+func (s *Service) UpdateIfWithOptions(ctx context.Context, blueprint, identifier string, opts UpdateIfOptions, mutate func(*Entity) error) (Entity, error) {
+	if mutate == nil {
+		return Entity{}, fmt.Errorf("entities: UpdateIf requires a mutate function")
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultUpdateIfAttempts
+	}
+	cur, err := s.Get(ctx, blueprint, identifier)
+	if err != nil {
+		return Entity{}, err
+	}
+	if !opts.ExpectedUpdatedAt.IsZero() && !cur.UpdatedAt.Equal(opts.ExpectedUpdatedAt) {
+		return Entity{}, &ConflictError{
+			Blueprint:  blueprint,
+			Identifier: identifier,
+			Expected:   opts.ExpectedUpdatedAt,
+			Actual:     cur.UpdatedAt,
+			Attempts:   1,
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		next := cloneEntity(cur)
+		if err := mutate(&next); err != nil {
+			return Entity{}, err
+		}
+		diff := DiffEntities(cur, next)
+		if diff.Empty() {
+			return cur, nil
+		}
+		latest, err := s.Get(ctx, blueprint, identifier)
+		if err != nil {
+			return Entity{}, err
+		}
+		if !sameVersion(cur, latest) {
+			if attempt >= maxAttempts {
+				return Entity{}, &ConflictError{
+					Blueprint:  blueprint,
+					Identifier: identifier,
+					Expected:   cur.UpdatedAt,
+					Actual:     latest.UpdatedAt,
+					Attempts:   attempt,
+				}
+			}
+			cur = latest
+			continue
+		}
+		path := fmt.Sprintf("/v1/blueprints/%s/entities/%s", url.PathEscape(blueprint), url.PathEscape(identifier))
+		var resp entityResponse
+		if err := s.doer.Do(ctx, "PATCH", path, diffPayload(next, diff), &resp); err != nil {
+			return Entity{}, err
+		}
+		if resp.Entity.Identifier != "" {
+			return resp.Entity, nil
+		}
+		return next, nil
+	}
+}
+
+// diffPayload builds a PATCH body containing only the fields in diff.
+func diffPayload(next Entity, diff *EntityDiff) map[string]any {
+	payload := map[string]any{}
+	for field := range diff.Fields {
+		switch field {
+		case "title":
+			payload["title"] = next.Title
+		case "icon":
+			payload["icon"] = next.Icon
+		case "team":
+			payload["team"] = next.Team
+		}
+	}
+	if len(diff.Properties) > 0 {
+		props := make(map[string]any, len(diff.Properties))
+		for key, change := range diff.Properties {
+			if _, ok := next.Properties[key]; !ok {
+				props[key] = Unset
+				continue
+			}
+			props[key] = change.New
+		}
+		payload["properties"] = props
+	}
+	if len(diff.Relations) > 0 {
+		rels := make(map[string]any, len(diff.Relations))
+		for key := range diff.Relations {
+			if _, ok := next.Relations[key]; !ok {
+				rels[key] = Unset
+				continue
+			}
+			targets := make([]string, len(next.Relations[key]))
+			copy(targets, next.Relations[key])
+			rels[key] = targets
+		}
+		payload["relations"] = rels
+	}
+	return payload
+}
+
+// sameVersion compares two reads of an entity by updatedAt, falling back to a
+// content hash when the API did not return timestamps.
+func sameVersion(a, b Entity) bool {
+	if !a.UpdatedAt.IsZero() || !b.UpdatedAt.IsZero() {
+		return a.UpdatedAt.Equal(b.UpdatedAt)
+	}
+	return ContentHash(a) == ContentHash(b)
+}
+
+// ContentHash returns a stable SHA-256 over the entity's title, icon, team,
+// properties and relations. It can be used as a version token when updatedAt
+// is unavailable.
+func ContentHash(ent Entity) string {
+	//nolint:errcheck // maps of JSON-decoded values always marshal
+	raw, _ := json.Marshal(struct {
+		Title      string              `json:"title"`
+		Icon       string              `json:"icon"`
+		Team       Teams               `json:"team"`
+		Properties map[string]any      `json:"properties"`
+		Relations  map[string][]string `json:"relations"`
+	}{ent.Title, ent.Icon, ent.Team, ent.Properties, ent.Relations})
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// cloneEntity deep-copies the mutable maps of an entity so callers can edit
+// the copy without touching the original.
+func cloneEntity(ent Entity) Entity {
+	out := ent
+	out.Team = slices.Clone(ent.Team)
+	if ent.Properties != nil {
+		out.Properties = make(map[string]any, len(ent.Properties))
+		for k, v := range ent.Properties {
+			out.Properties[k] = deepCopyValue(v)
+		}
+	}
+	if ent.Relations != nil {
+		out.Relations = make(map[string][]string, len(ent.Relations))
+		for k, v := range ent.Relations {
+			out.Relations[k] = append([]string(nil), v...)
+		}
+	}
+	return out
+}
+
+func deepCopyValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = deepCopyValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = deepCopyValue(item)
+		}
+		return out
+	case []string:
+		return append([]string(nil), val...)
+	default:
+		return v
+	}
+}
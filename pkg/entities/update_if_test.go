@@ -0,0 +1,152 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// versionedDoer returns successive entity versions from gets and records writes.
+type versionedDoer struct {
+	gets   []Entity
+	calls  int
+	method string
+	body   any
+}
+
+func (d *versionedDoer) Do(ctx context.Context, method, path string, body any, out any) error {
+	if method == "GET" {
+		idx := d.calls
+		if idx >= len(d.gets) {
+			idx = len(d.gets) - 1
+		}
+		d.calls++
+		return respondEntity(out, d.gets[idx])
+	}
+	d.method = method
+	d.body = body
+	return nil
+}
+
+// respondEntity decodes ent into out in the shape Port returns for single
+// entity reads and writes.
+func respondEntity(out any, ent Entity) error {
+	raw, err := json.Marshal(map[string]any{"ok": true, "entity": ent})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func TestUpdateIfWritesOnlyChanges(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := Entity{Identifier: "svc", UpdatedAt: t0, Properties: map[string]any{"tier": "1", "lang": "go"}}
+	doer := &versionedDoer{gets: []Entity{base, base}}
+	got, err := New(doer).UpdateIf(context.Background(), "bp", "svc", t0, func(e *Entity) error {
+		e.Properties["tier"] = "2"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update if: %v", err)
+	}
+	if doer.method != "PATCH" {
+		t.Fatalf("expected PATCH, got %s", doer.method)
+	}
+	props := doer.body.(map[string]any)["properties"].(map[string]any)
+	if len(props) != 1 || props["tier"] != "2" {
+		t.Fatalf("unexpected payload %#v", doer.body)
+	}
+	if got.Properties["tier"] != "2" || base.Properties["tier"] != "1" {
+		t.Fatalf("mutation leaked or missing: got %v base %v", got.Properties, base.Properties)
+	}
+}
+
+func TestUpdateIfCopiesTeams(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	doer := &versionedDoer{gets: []Entity{{Identifier: "svc", UpdatedAt: t0, Team: Teams{"platform"}}}}
+	_, err := New(doer).UpdateIf(context.Background(), "bp", "svc", t0, func(e *Entity) error {
+		e.Team[0] = "payments"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update if: %v", err)
+	}
+	if doer.method != "PATCH" {
+		t.Fatalf("in-place team change not detected, got %q", doer.method)
+	}
+	if team := doer.body.(map[string]any)["team"].(Teams); len(team) != 1 || team[0] != "payments" {
+		t.Fatalf("unexpected payload %#v", doer.body)
+	}
+}
+
+func TestUpdateIfRetriesOnConcurrentWrite(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	v1 := Entity{Identifier: "svc", UpdatedAt: t0, Properties: map[string]any{"count": 1.0}}
+	v2 := Entity{Identifier: "svc", UpdatedAt: t0.Add(time.Second), Properties: map[string]any{"count": 5.0}}
+	doer := &versionedDoer{gets: []Entity{v1, v2, v2}}
+	runs := 0
+	_, err := New(doer).UpdateIf(context.Background(), "bp", "svc", time.Time{}, func(e *Entity) error {
+		runs++
+		e.Properties["count"] = e.Properties["count"].(float64) + 1
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update if: %v", err)
+	}
+	if runs != 2 {
+		t.Fatalf("expected mutate to rerun once, ran %d times", runs)
+	}
+	if got := doer.body.(map[string]any)["properties"].(map[string]any)["count"]; got != 6.0 {
+		t.Fatalf("expected write based on fresh read, got %v", got)
+	}
+}
+
+func TestUpdateIfConflict(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	doer := &versionedDoer{gets: []Entity{{Identifier: "svc", UpdatedAt: t0.Add(time.Minute)}}}
+	_, err := New(doer).UpdateIf(context.Background(), "bp", "svc", t0, func(*Entity) error { return nil })
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !conflict.Expected.Equal(t0) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+	if doer.method != "" {
+		t.Fatalf("no write expected, got %s", doer.method)
+	}
+}
+
+func TestUpdateIfMaxAttempts(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var gets []Entity
+	for i := 0; i < 10; i++ {
+		gets = append(gets, Entity{Identifier: "svc", UpdatedAt: t0.Add(time.Duration(i) * time.Second)})
+	}
+	doer := &versionedDoer{gets: gets}
+	runs := 0
+	_, err := New(doer).UpdateIfWithOptions(context.Background(), "bp", "svc", UpdateIfOptions{MaxAttempts: 2}, func(e *Entity) error {
+		runs++
+		e.Title = "changed"
+		return nil
+	})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Attempts != 2 || runs != 2 {
+		t.Fatalf("expected conflict after 2 attempts, got %v (runs %d)", err, runs)
+	}
+	if doer.method != "" {
+		t.Fatalf("no write expected, got %s", doer.method)
+	}
+}
+
+func TestGetDecodesEnvelope(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	doer := &versionedDoer{gets: []Entity{{Identifier: "svc", UpdatedAt: t0}}}
+	ent, err := New(doer).Get(context.Background(), "bp", "svc")
+	if err != nil || ent.Identifier != "svc" || !ent.UpdatedAt.Equal(t0) {
+		t.Fatalf("get: %+v %v", ent, err)
+	}
+	var bare entityResponse
+	if err := json.Unmarshal([]byte(`{"identifier":"svc"}`), &bare); err != nil || bare.Entity.Identifier != "svc" {
+		t.Fatalf("bare entity: %+v %v", bare, err)
+	}
+}
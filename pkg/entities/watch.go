@@ -0,0 +1,457 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventType describes the kind of change observed by Watch.
+type EventType string
+
+const (
+	// EventCreated is emitted when an entity starts matching the watched query.
+	EventCreated EventType = "created"
+	// EventUpdated is emitted when a tracked entity changes.
+	EventUpdated EventType = "updated"
+	// EventDeleted is emitted when an entity is removed or stops matching the query.
+	EventDeleted EventType = "deleted"
+)
+
+// Event is a single change detected by Watch.
+type Event struct {
+	Type EventType
+	// Entity is the current entity, or the last known version for EventDeleted.
+	Entity Entity
+	// Previous holds the last known version for EventUpdated.
+	Previous *Entity
+	// Diff lists the changed fields for EventUpdated.
+	Diff *EntityDiff
+}
+
+// EntityDiff lists changes between two versions of an entity.
+type EntityDiff struct {
+	Fields     map[string]ValueChange    `json:"fields,omitempty"`
+	Properties map[string]ValueChange    `json:"properties,omitempty"`
+	Relations  map[string]RelationChange `json:"relations,omitempty"`
+}
+
+// Empty reports whether the diff contains no changes.
+func (d *EntityDiff) Empty() bool {
+	return d == nil || (len(d.Fields) == 0 && len(d.Properties) == 0 && len(d.Relations) == 0)
+}
+
+// ValueChange records the old and new value of a field or property.
+type ValueChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// RelationChange records targets added to or removed from a relation.
+type RelationChange struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// WatchState is the resumable snapshot kept by Watch. It is JSON-serializable
+// so callers can persist it between process restarts.
+type WatchState struct {
+	Since    time.Time         `json:"since"`
+	Entities map[string]Entity `json:"entities"`
+}
+
+// WatchOptions configure Watch.
+type WatchOptions struct {
+	// Interval between polls. Defaults to 30 seconds.
+	Interval time.Duration
+	// Jitter adds a random delay in [0, Jitter) to every interval.
+	Jitter time.Duration
+	// Overlap widens the $updatedAt window to tolerate clock skew. Defaults to 1 minute.
+	Overlap time.Duration
+	// PageSize controls the search page size. Defaults to 100.
+	PageSize int
+
+	// Handler receives events synchronously. Returning an error stops Watch.
+	Handler func(ctx context.Context, ev Event) error
+	// Events receives events when Handler is nil. Sends block until the
+	// consumer is ready, pausing polling (backpressure).
+	Events chan<- Event
+
+	// State resumes a previous watch. When nil, the first poll seeds the
+	// snapshot without emitting events unless EmitInitial is set.
+	State       *WatchState
+	EmitInitial bool
+	// OnCheckpoint is called after every poll once all events are delivered.
+	OnCheckpoint func(WatchState)
+	// OnError is called for poll failures. When nil, Watch returns the error.
+	OnError func(error)
+}
+
+const (
+	defaultWatchInterval = 30 * time.Second
+	defaultWatchOverlap  = time.Minute
+	defaultWatchPageSize = 100
+)
+
+// Watch polls the blueprint for entities matching query and emits created,
+// updated and deleted events until ctx is canceled. Changed entities are
+// fetched using a $updatedAt window, while deletions are detected using an
+// identifiers-only snapshot.
+//
+// Example:
+//
+//	err := svc.Watch(ctx, "service", nil, entities.WatchOptions{
+//		Interval: time.Minute,
+//		Handler: func(ctx context.Context, ev entities.Event) error {
+//			log.Printf("%s %s", ev.Type, ev.Entity.Identifier)
+//			return nil
+//		},
+//	})
+func (s *Service) Watch(ctx context.Context, blueprint string, query map[string]any, opts WatchOptions) error {
+	if blueprint == "" {
+		return fmt.Errorf("entities: watch requires a blueprint")
+	}
+	if opts.Handler == nil && opts.Events == nil {
+		return fmt.Errorf("entities: watch requires a Handler or Events channel")
+	}
+	w := newWatcher(s, blueprint, query, opts)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+	for {
+		if err := w.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var herr *handlerError
+			if errors.As(err, &herr) {
+				return herr.err
+			}
+			if w.opts.OnError == nil {
+				return err
+			}
+			w.opts.OnError(err)
+		}
+		timer.Reset(w.nextDelay())
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// handlerError marks failures returned by WatchOptions.Handler.
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string { return e.err.Error() }
+
+func (e *handlerError) Unwrap() error { return e.err }
+
+type watcher struct {
+	svc       *Service
+	blueprint string
+	query     map[string]any
+	opts      WatchOptions
+	state     WatchState
+	seeded    bool
+	now       func() time.Time
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+func newWatcher(s *Service, blueprint string, query map[string]any, opts WatchOptions) *watcher {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultWatchInterval
+	}
+	if opts.Overlap <= 0 {
+		opts.Overlap = defaultWatchOverlap
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultWatchPageSize
+	}
+	w := &watcher{
+		svc:       s,
+		blueprint: blueprint,
+		query:     query,
+		opts:      opts,
+		now:       time.Now,
+		state:     WatchState{Entities: map[string]Entity{}},
+		//nolint:gosec // G404: math/rand is acceptable for non-cryptographic jitter
+		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if opts.State != nil {
+		w.state.Since = opts.State.Since
+		for id, ent := range opts.State.Entities {
+			w.state.Entities[id] = ent
+		}
+		w.seeded = true
+	}
+	return w
+}
+
+func (w *watcher) nextDelay() time.Duration {
+	if w.opts.Jitter <= 0 {
+		return w.opts.Interval
+	}
+	w.rngMu.Lock()
+	defer w.rngMu.Unlock()
+	return w.opts.Interval + time.Duration(w.rng.Int63n(int64(w.opts.Jitter)))
+}
+
+// poll runs a single watch cycle and delivers the resulting events.
+func (w *watcher) poll(ctx context.Context) error {
+	started := w.now()
+	var changed []Entity
+	var err error
+	if w.seeded {
+		changed, err = w.fetch(ctx, w.updatedSinceQuery(w.state.Since.Add(-w.opts.Overlap)), nil)
+	} else {
+		changed, err = w.fetch(ctx, w.query, nil)
+	}
+	if err != nil {
+		return err
+	}
+	current := make(map[string]Entity, len(changed))
+	for _, ent := range changed {
+		current[ent.Identifier] = ent
+	}
+
+	live := make(map[string]struct{}, len(w.state.Entities))
+	if w.seeded {
+		ids, err := w.fetch(ctx, w.query, []string{"identifier"})
+		if err != nil {
+			return err
+		}
+		var missing []string
+		for _, ent := range ids {
+			live[ent.Identifier] = struct{}{}
+			_, known := w.state.Entities[ent.Identifier]
+			_, fetched := current[ent.Identifier]
+			if !known && !fetched {
+				missing = append(missing, ent.Identifier)
+			}
+		}
+		if len(missing) > 0 {
+			extra, err := w.fetch(ctx, identifierQuery(missing), nil)
+			if err != nil {
+				return err
+			}
+			for _, ent := range extra {
+				current[ent.Identifier] = ent
+			}
+		}
+	} else {
+		for id := range current {
+			live[id] = struct{}{}
+		}
+	}
+
+	next := make(map[string]Entity, len(w.state.Entities))
+	for id, ent := range w.state.Entities {
+		next[id] = ent
+	}
+	events := diffSnapshot(next, current, live)
+	if w.seeded || w.opts.EmitInitial {
+		for _, ev := range events {
+			if err := w.emit(ctx, ev); err != nil {
+				return err
+			}
+		}
+	}
+	// State is only committed once every event has been delivered so a
+	// failed handler sees the same events again on the next poll.
+	w.seeded = true
+	w.state.Entities = next
+	w.state.Since = started
+	if w.opts.OnCheckpoint != nil {
+		w.opts.OnCheckpoint(w.snapshot())
+	}
+	return nil
+}
+
+// diffSnapshot folds the fetched entities into state and returns the resulting
+// events. An entity fetched as changed but already gone from the live set was
+// deleted during the poll: it yields a single deleted event if it was known,
+// and no event at all if it was created and deleted within the poll window.
+func diffSnapshot(state, current map[string]Entity, live map[string]struct{}) []Event {
+	var events []Event
+	for _, id := range sortedKeys(current) {
+		ent := current[id]
+		prev, known := state[id]
+		if _, ok := live[id]; !ok {
+			if known {
+				state[id] = ent
+			}
+			continue
+		}
+		state[id] = ent
+		if !known {
+			events = append(events, Event{Type: EventCreated, Entity: ent})
+			continue
+		}
+		diff := DiffEntities(prev, ent)
+		if diff.Empty() {
+			continue
+		}
+		prevCopy := prev
+		events = append(events, Event{Type: EventUpdated, Entity: ent, Previous: &prevCopy, Diff: diff})
+	}
+	for _, id := range sortedKeys(state) {
+		if _, ok := live[id]; ok {
+			continue
+		}
+		events = append(events, Event{Type: EventDeleted, Entity: state[id]})
+		delete(state, id)
+	}
+	return events
+}
+
+func (w *watcher) emit(ctx context.Context, ev Event) error {
+	if w.opts.Handler != nil {
+		if err := w.opts.Handler(ctx, ev); err != nil {
+			return &handlerError{err: err}
+		}
+		return nil
+	}
+	select {
+	case w.opts.Events <- ev:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *watcher) snapshot() WatchState {
+	out := WatchState{Since: w.state.Since, Entities: make(map[string]Entity, len(w.state.Entities))}
+	for id, ent := range w.state.Entities {
+		out.Entities[id] = ent
+	}
+	return out
+}
+
+func (w *watcher) fetch(ctx context.Context, query map[string]any, include []string) ([]Entity, error) {
+	if query == nil {
+		query = map[string]any{"combinator": "and", "rules": []any{}}
+	}
+	return w.svc.ListAllBlueprint(ctx, w.blueprint, SearchOptions{
+		Query:   query,
+		Include: include,
+		Limit:   w.opts.PageSize,
+	})
+}
+
+func (w *watcher) updatedSinceQuery(since time.Time) map[string]any {
+	rules := []any{map[string]any{
+		"property": "$updatedAt",
+		"operator": "between",
+		"value": map[string]any{
+			"from": since.UTC().Format(time.RFC3339),
+			"to":   w.now().Add(w.opts.Overlap).UTC().Format(time.RFC3339),
+		},
+	}}
+	if w.query != nil {
+		rules = append(rules, w.query)
+	}
+	return map[string]any{"combinator": "and", "rules": rules}
+}
+
+func identifierQuery(ids []string) map[string]any {
+	return map[string]any{
+		"combinator": "and",
+		"rules": []any{map[string]any{
+			"property": "$identifier",
+			"operator": "in",
+			"value":    ids,
+		}},
+	}
+}
+
+// DiffEntities compares two versions of an entity and reports the changed
+// title/icon/team fields, properties and relation targets.
+func DiffEntities(prev, next Entity) *EntityDiff {
+	diff := &EntityDiff{}
+	addField := func(name string, old, cur any) {
+		if reflect.DeepEqual(old, cur) {
+			return
+		}
+		if diff.Fields == nil {
+			diff.Fields = map[string]ValueChange{}
+		}
+		diff.Fields[name] = ValueChange{Old: old, New: cur}
+	}
+	addField("title", prev.Title, next.Title)
+	addField("icon", prev.Icon, next.Icon)
+	addField("team", prev.Team, next.Team)
+
+	for key, cur := range next.Properties {
+		old, ok := prev.Properties[key]
+		if ok && reflect.DeepEqual(old, cur) {
+			continue
+		}
+		if diff.Properties == nil {
+			diff.Properties = map[string]ValueChange{}
+		}
+		diff.Properties[key] = ValueChange{Old: old, New: cur}
+	}
+	for key, old := range prev.Properties {
+		if _, ok := next.Properties[key]; ok {
+			continue
+		}
+		if diff.Properties == nil {
+			diff.Properties = map[string]ValueChange{}
+		}
+		diff.Properties[key] = ValueChange{Old: old}
+	}
+
+	names := map[string]struct{}{}
+	for key := range prev.Relations {
+		names[key] = struct{}{}
+	}
+	for key := range next.Relations {
+		names[key] = struct{}{}
+	}
+	for key := range names {
+		added := stringsMinus(next.Relations[key], prev.Relations[key])
+		removed := stringsMinus(prev.Relations[key], next.Relations[key])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		if diff.Relations == nil {
+			diff.Relations = map[string]RelationChange{}
+		}
+		diff.Relations[key] = RelationChange{Added: added, Removed: removed}
+	}
+	return diff
+}
+
+func stringsMinus(a, b []string) []string {
+	skip := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		skip[v] = struct{}{}
+	}
+	var out []string
+	for _, v := range a {
+		if _, ok := skip[v]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]Entity) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+// watchDoer serves blueprint searches from an in-memory catalog. Entities in
+// changed are returned for $updatedAt queries; everything else comes from db.
+type watchDoer struct {
+	db      map[string]Entity
+	changed map[string]bool
+}
+
+func (d *watchDoer) Do(ctx context.Context, method, path string, body any, out any) error {
+	resp, ok := out.(*ListResponse)
+	if !ok {
+		return nil
+	}
+	req := body.(map[string]any)
+	query, _ := req["query"].(map[string]any)
+	ids := make([]string, 0, len(d.db))
+	for id := range d.db {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		ent := d.db[id]
+		if hasRule(query, "$updatedAt") && !d.changed[id] {
+			continue
+		}
+		if in := ruleValue(query, "$identifier"); in != nil && !contains(in.([]string), id) {
+			continue
+		}
+		if _, ok := req["include"]; ok {
+			ent = Entity{Identifier: id}
+		}
+		resp.Entities = append(resp.Entities, ent)
+	}
+	resp.OK = true
+	return nil
+}
+
+func hasRule(query map[string]any, property string) bool {
+	return ruleValue(query, property) != nil
+}
+
+func ruleValue(query map[string]any, property string) any {
+	rules, _ := query["rules"].([]any)
+	for _, r := range rules {
+		if rule, ok := r.(map[string]any); ok && rule["property"] == property {
+			return rule["value"]
+		}
+	}
+	return nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWatcherEmitsEvents(t *testing.T) {
+	doer := &watchDoer{db: map[string]Entity{
+		"a": {Identifier: "a", Properties: map[string]any{"tier": "1"}},
+		"b": {Identifier: "b", Relations: map[string][]string{"owner": {"x"}}},
+	}}
+	var events []Event
+	var checkpoint WatchState
+	w := newWatcher(New(doer), "svc", nil, WatchOptions{
+		Handler: func(_ context.Context, ev Event) error {
+			events = append(events, ev)
+			return nil
+		},
+		OnCheckpoint: func(s WatchState) { checkpoint = s },
+	})
+	ctx := context.Background()
+	if err := w.poll(ctx); err != nil {
+		t.Fatalf("seed poll: %v", err)
+	}
+	if len(events) != 0 || len(checkpoint.Entities) != 2 {
+		t.Fatalf("seed should be silent, got %d events, %d tracked", len(events), len(checkpoint.Entities))
+	}
+
+	doer.db["a"] = Entity{Identifier: "a", Properties: map[string]any{"tier": "2"}}
+	doer.db["b"] = Entity{Identifier: "b", Relations: map[string][]string{"owner": {"y"}}}
+	doer.db["c"] = Entity{Identifier: "c"}
+	doer.changed = map[string]bool{"a": true}
+	if err := w.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	// b changed outside the $updatedAt window so only a and c (found via the
+	// identifier snapshot) are reported.
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].Type != EventUpdated || events[0].Diff.Properties["tier"].New != "2" {
+		t.Fatalf("bad update event %+v", events[0])
+	}
+	if events[1].Type != EventCreated || events[1].Entity.Identifier != "c" {
+		t.Fatalf("bad create event %+v", events[1])
+	}
+
+	delete(doer.db, "a")
+	doer.changed = nil
+	events = nil
+	if err := w.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventDeleted || events[0].Entity.Identifier != "a" {
+		t.Fatalf("expected delete of a, got %+v", events)
+	}
+	if _, ok := checkpoint.Entities["a"]; ok {
+		t.Fatalf("deleted entity still tracked")
+	}
+}
+
+func TestWatcherRedeliversAfterHandlerError(t *testing.T) {
+	doer := &watchDoer{db: map[string]Entity{}}
+	fail := true
+	var delivered int
+	w := newWatcher(New(doer), "svc", nil, WatchOptions{
+		State: &WatchState{Since: time.Now(), Entities: map[string]Entity{}},
+		Handler: func(context.Context, Event) error {
+			if fail {
+				return errors.New("downstream unavailable")
+			}
+			delivered++
+			return nil
+		},
+	})
+	doer.db["a"] = Entity{Identifier: "a"}
+	doer.changed = map[string]bool{"a": true}
+	if err := w.poll(context.Background()); err == nil {
+		t.Fatalf("expected handler error")
+	}
+	fail = false
+	if err := w.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("expected event to be redelivered, got %d", delivered)
+	}
+}
+
+func TestDiffEntities(t *testing.T) {
+	prev := Entity{Title: "old", Properties: map[string]any{"a": 1, "b": 2}, Relations: map[string][]string{"r": {"x", "y"}}}
+	next := Entity{Title: "new", Properties: map[string]any{"a": 1, "c": 3}, Relations: map[string][]string{"r": {"y", "z"}}}
+	diff := DiffEntities(prev, next)
+	if diff.Fields["title"].New != "new" {
+		t.Fatalf("title change missing: %+v", diff.Fields)
+	}
+	if len(diff.Properties) != 2 || diff.Properties["b"].New != nil || diff.Properties["c"].New != 3 {
+		t.Fatalf("bad property diff: %+v", diff.Properties)
+	}
+	rel := diff.Relations["r"]
+	if len(rel.Added) != 1 || rel.Added[0] != "z" || len(rel.Removed) != 1 || rel.Removed[0] != "x" {
+		t.Fatalf("bad relation diff: %+v", rel)
+	}
+	if !DiffEntities(next, next).Empty() {
+		t.Fatalf("expected empty diff")
+	}
+}
+
+func TestDiffSnapshotCollapsesDeletedDuringPoll(t *testing.T) {
+	state := map[string]Entity{"known": {Identifier: "known", Title: "old"}}
+	// Both entities were returned by the $updatedAt fetch but are missing
+	// from the identifier snapshot taken afterwards.
+	current := map[string]Entity{
+		"known":     {Identifier: "known", Title: "new"},
+		"ephemeral": {Identifier: "ephemeral"},
+	}
+	events := diffSnapshot(state, current, map[string]struct{}{})
+	if len(events) != 1 || events[0].Type != EventDeleted || events[0].Entity.Title != "new" {
+		t.Fatalf("expected a single delete of known, got %+v", events)
+	}
+	if len(state) != 0 {
+		t.Fatalf("expected empty state, got %+v", state)
+	}
+}
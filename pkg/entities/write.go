@@ -0,0 +1,274 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Unset marks a property or relation for removal. It encodes as JSON null,
+// which Port treats as "clear this value" on merge writes and patches.
+//
+//	svc.Patch(ctx, "service", "api", entities.Patch{
+//		Properties: map[string]any{"deprecatedField": entities.Unset},
+//		Relations:  map[string]any{"owner": entities.Unset},
+//	}, nil)
+var Unset = unsetValue{}
+
+type unsetValue struct{}
+
+// MarshalJSON encodes the sentinel as null.
+func (unsetValue) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// IsUnset reports whether v is the Unset sentinel.
+func IsUnset(v any) bool {
+	_, ok := v.(unsetValue)
+	return ok
+}
+
+// WriteMode selects how an upsert treats an existing entity.
+type WriteMode string
+
+const (
+	// WriteMerge keeps properties and relations that are not part of the payload.
+	WriteMerge WriteMode = "merge"
+	// WriteReplace overwrites the entity; omitted properties and relations are cleared.
+	WriteReplace WriteMode = "replace"
+)
+
+// WriteOptions control entity create, upsert and patch calls.
+type WriteOptions struct {
+	// Upsert updates the entity when it already exists instead of failing.
+	Upsert bool
+	// Mode selects merge (default) or replace semantics for upserts.
+	Mode WriteMode
+	// CreateMissingRelatedEntities creates placeholder entities for unknown relation targets.
+	CreateMissingRelatedEntities bool
+	// RunID attributes the change to an action run.
+	RunID string
+	// ValidationOnly validates the payload without persisting it.
+	ValidationOnly bool
+}
+
+// query encodes the options in a stable order. upsert/merge only apply to
+// POST writes, so they are skipped when forPost is false.
+func (o *WriteOptions) query(forPost bool) string {
+	if o == nil {
+		return ""
+	}
+	var parts []string
+	if forPost && o.Upsert {
+		parts = append(parts,
+			"upsert=true",
+			"merge="+strconv.FormatBool(o.Mode != WriteReplace),
+		)
+	}
+	if o.CreateMissingRelatedEntities {
+		parts = append(parts, "create_missing_related_entities=true")
+	}
+	if o.RunID != "" {
+		parts = append(parts, "run_id="+url.QueryEscape(o.RunID))
+	}
+	if o.ValidationOnly {
+		parts = append(parts, "validation_only=true")
+	}
+	return strings.Join(parts, "&")
+}
+
+// Write creates an entity, or upserts it when opts.Upsert is set. With
+// WriteReplace, properties set to Unset are simply omitted since the write
+// replaces the whole entity anyway.
+// The context controls the request lifetime. Recommended timeout: 30 seconds.
+func (s *Service) Write(ctx context.Context, blueprint string, ent Entity, opts *WriteOptions) error {
+	path := fmt.Sprintf("/v1/blueprints/%s/entities", url.PathEscape(blueprint))
+	if qs := opts.query(true); qs != "" {
+		path += "?" + qs
+	}
+	payload := entityPayload(ent)
+	if opts != nil && opts.Mode == WriteReplace {
+		payload["properties"] = withoutUnset(ent.Properties)
+	}
+	return s.doer.Do(ctx, "POST", path, payload, nil)
+}
+
+// Patch describes a partial entity update. Only non-nil fields are sent.
+// Team may be a team name, a Teams value or Unset.
+// Property and relation values may be Unset to clear them; relation values
+// are otherwise a target identifier (string) or a list of identifiers ([]string).
+type Patch struct {
+	Title      *string
+	Icon       *string
+	Team       any
+	Properties map[string]any
+	Relations  map[string]any
+}
+
+func (p Patch) payload() map[string]any {
+	payload := map[string]any{}
+	if p.Title != nil {
+		payload["title"] = *p.Title
+	}
+	if p.Icon != nil {
+		payload["icon"] = *p.Icon
+	}
+	if p.Team != nil {
+		payload["team"] = p.Team
+	}
+	if len(p.Properties) > 0 {
+		payload["properties"] = p.Properties
+	}
+	if len(p.Relations) > 0 {
+		payload["relations"] = p.Relations
+	}
+	return payload
+}
+
+// Patch applies a partial update to an entity. Unlike Update it never falls
+// back to PUT, so a rejected patch cannot overwrite unrelated fields.
+// The context controls the request lifetime. Recommended timeout: 30 seconds.
+func (s *Service) Patch(ctx context.Context, blueprint, identifier string, patch Patch, opts *WriteOptions) error {
+	payload := patch.payload()
+	if len(payload) == 0 {
+		return fmt.Errorf("entities: patch for %s/%s is empty", blueprint, identifier)
+	}
+	path := fmt.Sprintf("/v1/blueprints/%s/entities/%s", url.PathEscape(blueprint), url.PathEscape(identifier))
+	if qs := opts.query(false); qs != "" {
+		path += "?" + qs
+	}
+	return s.doer.Do(ctx, "PATCH", path, payload, nil)
+}
+
+// MergePatch applies an RFC 7386 JSON Merge Patch document to an entity. The
+// document uses the entity shape (title, icon, team, properties, relations);
+// null removes a value and nested objects inside properties are merged
+// recursively against the current entity.
+//
+//	doc := []byte(`{"properties":{"owner":null,"config":{"replicas":3}}}`)
+//	err := svc.MergePatch(ctx, "service", "api", doc, nil)
+func (s *Service) MergePatch(ctx context.Context, blueprint, identifier string, doc []byte, opts *WriteOptions) error {
+	var patch map[string]any
+	if err := json.Unmarshal(doc, &patch); err != nil {
+		return fmt.Errorf("entities: invalid merge patch: %w", err)
+	}
+	var out Patch
+	for key, value := range patch {
+		switch key {
+		case "title", "icon":
+			str, err := mergePatchString(key, value)
+			if err != nil {
+				return err
+			}
+			if key == "title" {
+				out.Title = &str
+			} else {
+				out.Icon = &str
+			}
+		case "team":
+			out.Team = nullToUnset(value)
+		case "properties", "relations":
+			if value == nil {
+				return fmt.Errorf("entities: merge patch cannot remove %q as a whole", key)
+			}
+			if _, ok := value.(map[string]any); !ok {
+				return fmt.Errorf("entities: merge patch %q must be an object", key)
+			}
+		default:
+			return fmt.Errorf("entities: merge patch field %q is not supported", key)
+		}
+	}
+
+	if rels, ok := patch["relations"].(map[string]any); ok {
+		out.Relations = make(map[string]any, len(rels))
+		for name, value := range rels {
+			out.Relations[name] = nullToUnset(value)
+		}
+	}
+	if props, ok := patch["properties"].(map[string]any); ok {
+		var current map[string]any
+		if needsCurrent(props) {
+			ent, err := s.Get(ctx, blueprint, identifier)
+			if err != nil {
+				return err
+			}
+			current = ent.Properties
+		}
+		out.Properties = make(map[string]any, len(props))
+		for name, value := range props {
+			if value == nil {
+				out.Properties[name] = Unset
+				continue
+			}
+			out.Properties[name] = applyMergePatch(current[name], value)
+		}
+	}
+	return s.Patch(ctx, blueprint, identifier, out, opts)
+}
+
+// applyMergePatch implements the RFC 7386 MergePatch algorithm.
+func applyMergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+	result := make(map[string]any, len(targetObj)+len(patchObj))
+	for k, v := range targetObj {
+		result[k] = v
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = applyMergePatch(result[k], v)
+	}
+	return result
+}
+
+func needsCurrent(props map[string]any) bool {
+	for _, v := range props {
+		if _, ok := v.(map[string]any); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func mergePatchString(field string, value any) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("entities: merge patch %q must be a string", field)
+	}
+	return str, nil
+}
+
+func nullToUnset(v any) any {
+	if v == nil {
+		return Unset
+	}
+	return v
+}
+
+func withoutUnset(props map[string]any) map[string]any {
+	if props == nil {
+		return nil
+	}
+	out := make(map[string]any, len(props))
+	for k, v := range props {
+		if !IsUnset(v) {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteOptionsQuery(t *testing.T) {
+	stub := &stubDoer{}
+	svc := New(stub)
+	ent := Entity{Identifier: "demo", Properties: map[string]any{"keep": 1, "drop": Unset}}
+	opts := &WriteOptions{
+		Upsert:                       true,
+		Mode:                         WriteReplace,
+		CreateMissingRelatedEntities: true,
+		RunID:                        "r_1",
+		ValidationOnly:               true,
+	}
+	if err := svc.Write(context.Background(), "bp", ent, opts); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := "/v1/blueprints/bp/entities?upsert=true&merge=false&create_missing_related_entities=true&run_id=r_1&validation_only=true"
+	if stub.path != want {
+		t.Fatalf("unexpected path %s", stub.path)
+	}
+	props := stub.body.(map[string]any)["properties"].(map[string]any)
+	if _, ok := props["drop"]; ok || props["keep"] != 1 {
+		t.Fatalf("replace write should omit unset properties: %#v", props)
+	}
+
+	if err := svc.Create(context.Background(), "bp", ent); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if stub.path != "/v1/blueprints/bp/entities" {
+		t.Fatalf("unexpected create path %s", stub.path)
+	}
+}
+
+func TestPatchEncodesUnsetAsNull(t *testing.T) {
+	stub := &stubDoer{}
+	svc := New(stub)
+	title := "API"
+	err := svc.Patch(context.Background(), "bp", "api", Patch{
+		Title:      &title,
+		Properties: map[string]any{"old": Unset, "tier": "gold"},
+		Relations:  map[string]any{"owner": Unset, "deps": []string{"db"}},
+	}, &WriteOptions{RunID: "r_2", Upsert: true})
+	if err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	if stub.method != "PATCH" || stub.path != "/v1/blueprints/bp/entities/api?run_id=r_2" {
+		t.Fatalf("unexpected call %s %s", stub.method, stub.path)
+	}
+	raw, err := json.Marshal(stub.body)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"properties":{"old":null,"tier":"gold"},"relations":{"deps":["db"],"owner":null},"title":"API"}`
+	if string(raw) != want {
+		t.Fatalf("payload mismatch\n got %s\nwant %s", raw, want)
+	}
+	if err := svc.Patch(context.Background(), "bp", "api", Patch{}, nil); err == nil {
+		t.Fatalf("expected error for empty patch")
+	}
+}
+
+type mergeDoer struct {
+	current Entity
+	body    any
+}
+
+func (d *mergeDoer) Do(ctx context.Context, method, path string, body any, out any) error {
+	if method == "GET" {
+		return respondEntity(out, d.current)
+	}
+	d.body = body
+	return nil
+}
+
+func TestMergePatch(t *testing.T) {
+	doer := &mergeDoer{current: Entity{Properties: map[string]any{
+		"config": map[string]any{"replicas": 1.0, "region": "eu", "debug": true},
+	}}}
+	doc := []byte(`{"title":"New","properties":{"owner":null,"config":{"replicas":3,"debug":null}},"relations":{"team":null}}`)
+	if err := New(doer).MergePatch(context.Background(), "bp", "api", doc, nil); err != nil {
+		t.Fatalf("merge patch: %v", err)
+	}
+	raw, err := json.Marshal(doer.body)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"properties":{"config":{"region":"eu","replicas":3},"owner":null},"relations":{"team":null},"title":"New"}`
+	if string(raw) != want {
+		t.Fatalf("payload mismatch\n got %s\nwant %s", raw, want)
+	}
+	if err := New(doer).MergePatch(context.Background(), "bp", "api", []byte(`{"identifier":"x"}`), nil); err == nil {
+		t.Fatalf("expected unsupported field error")
+	}
+}
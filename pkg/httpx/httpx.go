@@ -7,11 +7,8 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"math"
-	"math/rand"
 	"net"
 	"net/http"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,13 +18,6 @@ import (
 
 var defaultUserAgent = version.UserAgent()
 
-// rng is a package-level random number generator for jitter calculations.
-// Using a local rand.Rand instance instead of the deprecated global rand.Seed.
-// This is for non-cryptographic use (retry jitter), so math/rand is appropriate.
-//
-//nolint:gosec // G404: math/rand is acceptable for non-cryptographic jitter
-var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
-
 // Doer matches http.Client.Do.
 type Doer interface {
 	Do(*http.Request) (*http.Response, error)
@@ -65,46 +55,15 @@ func SetUserAgent(req *http.Request, ua string) {
 	}
 }
 
-// DoWithRetry retries on 5xx/429 with exponential backoff + jitter.
+// DoWithRetry sends req with DefaultRetryPolicy(attempts): 429 and 5xx
+// responses and transport errors are retried with exponential backoff and
+// jitter, honoring Retry-After. Non-idempotent requests are only retried when
+// the server cannot have processed them. See DoWithPolicy.
 func DoWithRetry(ctx context.Context, client Doer, req *http.Request, attempts int) (*http.Response, error) {
 	if attempts <= 0 {
 		attempts = 1
 	}
-	var err error
-	var resp *http.Response
-	for i := 1; i <= attempts; i++ {
-		cloned, cerr := cloneRequest(req)
-		if cerr != nil {
-			return nil, cerr
-		}
-		resp, err = client.Do(cloned)
-		if err == nil && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
-			return resp, nil
-		}
-		var wait time.Duration
-		if resp != nil {
-			if ra := resp.Header.Get("Retry-After"); ra != "" {
-				if seconds, parseErr := strconv.Atoi(ra); parseErr == nil {
-					wait = time.Duration(seconds) * time.Second
-				}
-			}
-			_ = resp.Body.Close()
-		}
-		if wait == 0 {
-			backoff := time.Duration(math.Pow(2, float64(i-1))) * time.Second
-			jitter := time.Duration(rng.Intn(500)) * time.Millisecond
-			wait = backoff + jitter
-		}
-		select {
-		case <-time.After(wait):
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		}
-	}
-	if err == nil && resp != nil {
-		return resp, fmt.Errorf("max retries exceeded: %s", resp.Status)
-	}
-	return nil, err
+	return DoWithPolicy(ctx, client, req, DefaultRetryPolicy(attempts))
 }
 
 // cloneRequest copies the request and body so it can be retried.
@@ -0,0 +1,292 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides whether a failed attempt is retried and how long to
+// wait before the next one. DoWithPolicy honors a Retry-After header (in
+// seconds or HTTP-date form) instead of Backoff when the server sends one.
+type RetryPolicy interface {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts() int
+	// MaxElapsed bounds the total time spent including waits. Zero means no
+	// limit.
+	MaxElapsed() time.Duration
+	// Retryable reports whether an attempt may be retried. resp is nil when
+	// err is non-nil.
+	Retryable(req *http.Request, resp *http.Response, err error) bool
+	// Backoff returns the wait after the given failed attempt (1-based).
+	Backoff(attempt int) time.Duration
+}
+
+// ExponentialBackoff is the default RetryPolicy. Zero fields use the
+// defaults noted below.
+//
+// Non-idempotent requests (POST and PATCH without an Idempotency-Key header
+// or a ContextWithIdempotent context) are only retried when the server cannot have processed them: on 429 and
+// when the connection could not be established. Set RetryNonIdempotent to
+// retry them like any other request.
+type ExponentialBackoff struct {
+	// Attempts is the total number of attempts. Defaults to 3.
+	Attempts int
+	// InitialInterval is the wait after the first failure. Defaults to 1s.
+	InitialInterval time.Duration
+	// MaxInterval caps a single wait. Defaults to 30s.
+	MaxInterval time.Duration
+	// Multiplier grows the wait per attempt. Defaults to 2.
+	Multiplier float64
+	// Jitter adds a random wait of up to this duration.
+	Jitter time.Duration
+	// MaxElapsedTime bounds the total time spent. Zero means no limit.
+	MaxElapsedTime time.Duration
+	// RetryStatuses lists retryable status codes. Defaults to 429 and 5xx.
+	RetryStatuses []int
+	// RetryError reports whether a transport error is retryable. Defaults
+	// to IsRetryableError.
+	RetryError func(error) bool
+	// RetryNonIdempotent retries POST and PATCH requests on any retryable
+	// failure.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the policy used by DoWithRetry: exponential
+// backoff from 1s with up to 500ms of jitter.
+func DefaultRetryPolicy(attempts int) *ExponentialBackoff {
+	return &ExponentialBackoff{Attempts: attempts, Jitter: 500 * time.Millisecond}
+}
+
+// MaxAttempts implements RetryPolicy.
+func (p *ExponentialBackoff) MaxAttempts() int {
+	if p.Attempts <= 0 {
+		return 3
+	}
+	return p.Attempts
+}
+
+// MaxElapsed implements RetryPolicy.
+func (p *ExponentialBackoff) MaxElapsed() time.Duration {
+	return p.MaxElapsedTime
+}
+
+// Backoff implements RetryPolicy.
+func (p *ExponentialBackoff) Backoff(attempt int) time.Duration {
+	initial, maxInterval, mult := p.InitialInterval, p.MaxInterval, p.Multiplier
+	if initial <= 0 {
+		initial = time.Second
+	}
+	if maxInterval <= 0 {
+		maxInterval = 30 * time.Second
+	}
+	if mult < 1 {
+		mult = 2
+	}
+	wait := time.Duration(float64(initial) * math.Pow(mult, float64(attempt-1)))
+	if wait > maxInterval || wait <= 0 {
+		wait = maxInterval
+	}
+	if p.Jitter > 0 {
+		//nolint:gosec // G404: math/rand is acceptable for non-cryptographic jitter
+		wait += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+	return wait
+}
+
+// Retryable implements RetryPolicy.
+func (p *ExponentialBackoff) Retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		retryErr := p.RetryError
+		if retryErr == nil {
+			retryErr = IsRetryableError
+		}
+		if !retryErr(err) {
+			return false
+		}
+		return p.RetryNonIdempotent || IsIdempotent(req) || isDialError(err)
+	}
+	if !p.retryStatus(resp.StatusCode) {
+		return false
+	}
+	return p.RetryNonIdempotent || IsIdempotent(req) || resp.StatusCode == http.StatusTooManyRequests
+}
+
+func (p *ExponentialBackoff) retryStatus(code int) bool {
+	if p.RetryStatuses == nil {
+		return code == http.StatusTooManyRequests || code >= 500
+	}
+	for _, c := range p.RetryStatuses {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIdempotent reports whether req may be repeated safely: GET, HEAD,
+// OPTIONS, TRACE, PUT and DELETE, any request made with a
+// ContextWithIdempotent context, or any request carrying an Idempotency-Key
+// header.
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if marked, _ := req.Context().Value(idempotentKey{}).(bool); marked {
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// IsRetryableError reports whether a transport error is worth retrying.
+// Context cancellation and TLS certificate failures are not.
+func IsRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var certErr *tls.CertificateVerificationError
+	return !errors.As(err, &certErr)
+}
+
+// isDialError reports whether the request failed before it was sent.
+func isDialError(err error) bool {
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
+
+// RetryExhaustedError is returned when every attempt allowed by the policy
+// failed. The last response, if any, is closed; its status and the start of
+// its body are kept here.
+type RetryExhaustedError struct {
+	// Attempts is the number of attempts made.
+	Attempts int
+	// Elapsed is the total time spent, including waits.
+	Elapsed time.Duration
+	// StatusCode and Status describe the last response. StatusCode is 0
+	// when the last attempt failed without a response.
+	StatusCode int
+	Status     string
+	// Body holds up to 64 KiB of the last response body.
+	Body []byte
+	// Err is the last transport error, or an error describing the last
+	// response set by the caller (the Port client sets a *porter.Error).
+	Err error
+}
+
+func (e *RetryExhaustedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("retries exhausted after %d attempts: %v", e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("retries exhausted after %d attempts: %s", e.Attempts, e.Status)
+}
+
+// Unwrap returns the last error.
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+type attemptKey struct{}
+
+type idempotentKey struct{}
+
+// ContextWithIdempotent marks requests made with ctx as safe to repeat. Use
+// it for POST routes that only read, such as searches, so they are retried
+// like GET requests without sending an Idempotency-Key.
+func ContextWithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// Attempt returns the 1-based attempt number of a request sent by
+// DoWithPolicy, or 0 for requests sent otherwise.
+func Attempt(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+	return n
+}
+
+// maxErrorBody bounds the response body kept in RetryExhaustedError.
+const maxErrorBody = 64 << 10
+
+// DoWithPolicy sends req, retrying according to policy. It returns the first
+// response that is not retried, open for the caller to read. Errors that are
+// not retried are returned as is; when the policy runs out of attempts or
+// time, a *RetryExhaustedError is returned and no response.
+func DoWithPolicy(ctx context.Context, client Doer, req *http.Request, policy RetryPolicy) (*http.Response, error) {
+	if policy == nil {
+		policy = DefaultRetryPolicy(3)
+	}
+	attempts := policy.MaxAttempts()
+	if attempts <= 0 {
+		attempts = 1
+	}
+	start := time.Now()
+	for i := 1; ; i++ {
+		cloned, err := cloneRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		cloned = cloned.WithContext(context.WithValue(cloned.Context(), attemptKey{}, i))
+		resp, err := client.Do(cloned)
+		if !policy.Retryable(req, resp, err) {
+			return resp, err
+		}
+		wait := policy.Backoff(i)
+		if resp != nil {
+			if ra, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = ra
+			}
+		}
+		limit := policy.MaxElapsed()
+		if i >= attempts || (limit > 0 && time.Since(start)+wait > limit) {
+			return nil, exhausted(i, time.Since(start), resp, err)
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			_ = resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func exhausted(attempts int, elapsed time.Duration, resp *http.Response, err error) *RetryExhaustedError {
+	e := &RetryExhaustedError{Attempts: attempts, Elapsed: elapsed, Err: err}
+	if resp != nil {
+		e.StatusCode = resp.StatusCode
+		e.Status = resp.Status
+		e.Body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		_ = resp.Body.Close()
+	}
+	return e
+}
+
+// ParseRetryAfter parses a Retry-After value in seconds or HTTP-date form
+// into a wait relative to now. Dates in the past yield zero.
+func ParseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,83 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func statusServer(t *testing.T, hits *int32, status int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"ok":false}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDoWithPolicyIdempotency(t *testing.T) {
+	fast := &ExponentialBackoff{Attempts: 3, InitialInterval: time.Millisecond}
+	var hits int32
+	srv := statusServer(t, &hits, http.StatusBadGateway)
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("{}"))
+	resp, err := DoWithPolicy(context.Background(), srv.Client(), req, fast)
+	if err != nil || resp.StatusCode != http.StatusBadGateway || hits != 1 {
+		t.Fatalf("POST should not be retried: hits=%d err=%v", hits, err)
+	}
+	resp.Body.Close()
+
+	hits = 0
+	req, _ = http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("{}"))
+	req.Header.Set("Idempotency-Key", "abc")
+	_, err = DoWithPolicy(context.Background(), srv.Client(), req, fast)
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) || hits != 3 {
+		t.Fatalf("keyed POST should be retried: hits=%d err=%v", hits, err)
+	}
+	if exhausted.Attempts != 3 || exhausted.StatusCode != http.StatusBadGateway || string(exhausted.Body) != `{"ok":false}` {
+		t.Fatalf("unexpected exhausted error %+v", exhausted)
+	}
+
+	hits = 0
+	req, _ = http.NewRequestWithContext(ContextWithIdempotent(context.Background()), http.MethodPost, srv.URL, strings.NewReader("{}"))
+	if _, err = DoWithPolicy(context.Background(), srv.Client(), req, fast); !errors.As(err, &exhausted) || hits != 3 {
+		t.Fatalf("read-only POST should be retried: hits=%d err=%v", hits, err)
+	}
+}
+
+func TestDoWithPolicyMaxElapsed(t *testing.T) {
+	var hits int32
+	srv := statusServer(t, &hits, http.StatusServiceUnavailable)
+	policy := &ExponentialBackoff{Attempts: 10, InitialInterval: 50 * time.Millisecond, MaxElapsedTime: 80 * time.Millisecond}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, http.NoBody)
+	_, err := DoWithPolicy(context.Background(), srv.Client(), req, policy)
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) || exhausted.Attempts != 2 {
+		t.Fatalf("expected to stop after 2 attempts, got %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"3":                             3 * time.Second,
+		"Wed, 01 Jan 2025 12:00:10 GMT": 10 * time.Second,
+		"Wed, 01 Jan 2025 11:00:00 GMT": 0,
+	}
+	for in, want := range cases {
+		if got, ok := ParseRetryAfter(in, now); !ok || got != want {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+	if _, ok := ParseRetryAfter("soon", now); ok {
+		t.Error("expected invalid Retry-After to be ignored")
+	}
+}
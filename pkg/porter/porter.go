@@ -1,7 +1,24 @@
 package porter
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+)
+
+// Sentinel errors matched by errors.Is against *Error, based on its status.
+// ErrAPI matches errors whose status has no more specific sentinel, such as
+// 402 or 405.
+var (
+	ErrNotFound     = errors.New("port: not found")
+	ErrConflict     = errors.New("port: conflict")
+	ErrRateLimited  = errors.New("port: rate limited")
+	ErrValidation   = errors.New("port: validation failed")
+	ErrUnauthorized = errors.New("port: unauthorized")
+	ErrForbidden    = errors.New("port: forbidden")
+	ErrServer       = errors.New("port: server error")
+	ErrAPI          = errors.New("port: api error")
 )
 
 // Error wraps Port API error responses with HTTP metadata.
@@ -9,21 +26,168 @@ type Error struct {
 	StatusCode int
 	Message    string
 	Body       []byte
+
+	// Code is Port's machine-readable error code (the "error" field), for
+	// example "not_found".
+	Code string
+	// Details holds the "details" object of the payload, if any.
+	Details map[string]any
+	// ValidationErrors lists per-field problems reported for invalid
+	// requests.
+	ValidationErrors []FieldError
+
+	// RequestID identifies the request for Port support.
+	RequestID string
+	// Method and Path describe the failed request; Route is its path with
+	// identifiers replaced by placeholders, such as
+	// /v1/blueprints/{blueprint}/entities/{entity}.
+	Method string
+	Path   string
+	Route  string
+}
+
+// FieldError is a validation problem with one field of a request body.
+type FieldError struct {
+	// Path locates the field, for example "properties.language".
+	Path    string
+	Message string
+}
+
+func (f FieldError) String() string {
+	if f.Path == "" {
+		return f.Message
+	}
+	return f.Path + ": " + f.Message
 }
 
 func (e *Error) Error() string {
 	if e == nil {
 		return "<nil>"
 	}
+	var b strings.Builder
+	b.WriteString("port api: ")
+	if e.Method != "" {
+		fmt.Fprintf(&b, "%s %s: ", e.Method, e.Path)
+	}
+	fmt.Fprintf(&b, "%d %s", e.StatusCode, httpStatusText(e.StatusCode))
 	if e.Message != "" {
-		return fmt.Sprintf("port api: %d %s: %s", e.StatusCode, httpStatusText(e.StatusCode), e.Message)
+		b.WriteString(": " + e.Message)
+	}
+	for i, f := range e.ValidationErrors {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(f.String())
 	}
-	return fmt.Sprintf("port api: %d %s", e.StatusCode, httpStatusText(e.StatusCode))
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request id %s)", e.RequestID)
+	}
+	return b.String()
 }
 
-// Unwrap allows the error to work with errors.Is and errors.As.
+// Unwrap returns the sentinel error for the status code, so that for example
+// errors.Is(err, ErrNotFound) reports a 404. 400 and 422 responses are
+// ErrValidation. Other statuses are ErrAPI.
 func (e *Error) Unwrap() error {
-	return nil
+	if e == nil {
+		return nil
+	}
+	switch code := e.StatusCode; {
+	case code == 400 || code == 422:
+		return ErrValidation
+	case code == 401:
+		return ErrUnauthorized
+	case code == 403:
+		return ErrForbidden
+	case code == 404:
+		return ErrNotFound
+	case code == 409:
+		return ErrConflict
+	case code == 429:
+		return ErrRateLimited
+	case code >= 500 && code < 600:
+		return ErrServer
+	}
+	return ErrAPI
+}
+
+// ParseError builds an Error from a response status and body, decoding
+// Port's JSON error payload ({"error": code, "message": ..., "details": ...})
+// when the body holds one. Validation errors are read from an "errors" list
+// at the top level or under "details".
+func ParseError(statusCode int, body []byte) *Error {
+	e := &Error{StatusCode: statusCode, Body: body}
+	var payload struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+		Errors  []any           `json:"errors"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return e
+	}
+	// "error" is usually a code but some endpoints send a message instead.
+	var code string
+	if json.Unmarshal(payload.Error, &code) == nil {
+		if payload.Message == "" && strings.Contains(code, " ") {
+			payload.Message = code
+		} else {
+			e.Code = code
+		}
+	}
+	e.Message = payload.Message
+	_ = json.Unmarshal(payload.Details, &e.Details)
+	items := payload.Errors
+	if list, ok := e.Details["errors"].([]any); ok && items == nil {
+		items = list
+	}
+	for _, item := range items {
+		if f, ok := fieldError(item); ok {
+			e.ValidationErrors = append(e.ValidationErrors, f)
+		}
+	}
+	return e
+}
+
+// fieldError reads a validation item such as {"path": ["properties", "x"],
+// "message": "..."} or {"instancePath": "/properties/x", "message": "..."}.
+func fieldError(item any) (FieldError, bool) {
+	switch v := item.(type) {
+	case string:
+		return FieldError{Message: v}, true
+	case map[string]any:
+		var f FieldError
+		for _, key := range []string{"message", "msg"} {
+			if s, ok := v[key].(string); ok {
+				f.Message = s
+				break
+			}
+		}
+		for _, key := range []string{"path", "instancePath", "dataPath", "field", "property"} {
+			if p := fieldPath(v[key]); p != "" {
+				f.Path = p
+				break
+			}
+		}
+		return f, f.Message != "" || f.Path != ""
+	}
+	return FieldError{}, false
+}
+
+func fieldPath(v any) string {
+	switch p := v.(type) {
+	case string:
+		return strings.ReplaceAll(strings.TrimPrefix(p, "/"), "/", ".")
+	case []any:
+		parts := make([]string, 0, len(p))
+		for _, part := range p {
+			parts = append(parts, fmt.Sprint(part))
+		}
+		return strings.Join(parts, ".")
+	}
+	return ""
 }
 
 func httpStatusText(code int) string {
@@ -1,6 +1,10 @@
 package porter
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
 
 func TestErrorString(t *testing.T) {
 	err := &Error{StatusCode: 404, Message: "not found"}
@@ -9,3 +13,38 @@ func TestErrorString(t *testing.T) {
 		t.Fatalf("want %q got %q", want, got)
 	}
 }
+
+func TestParseError(t *testing.T) {
+	body := []byte(`{"ok":false,"error":"invalid_request","message":"Entity is invalid",` +
+		`"details":{"blueprint":"service","errors":[{"instancePath":"/properties/tier","message":"must be string"},` +
+		`{"path":["relations","team"],"message":"required"}]}}`)
+	err := ParseError(422, body)
+	err.Method, err.Path, err.RequestID = "POST", "/v1/blueprints/service/entities", "req-1"
+	if err.Code != "invalid_request" || err.Details["blueprint"] != "service" {
+		t.Fatalf("decoded %+v", err)
+	}
+	want := "port api: POST /v1/blueprints/service/entities: 422 Unprocessable Entity: Entity is invalid: " +
+		"properties.tier: must be string; relations.team: required (request id req-1)"
+	if got := err.Error(); got != want {
+		t.Fatalf("want %q got %q", want, got)
+	}
+	if !errors.Is(err, ErrValidation) || errors.Is(err, ErrNotFound) {
+		t.Fatal("expected ErrValidation only")
+	}
+	wrapped := fmt.Errorf("upsert: %w", ParseError(404, []byte("not json")))
+	if !errors.Is(wrapped, ErrNotFound) || ErrorMessage(wrapped) != "HTTP 404: Not Found" {
+		t.Fatalf("unexpected %v", wrapped)
+	}
+}
+
+func TestUnmappedStatusIsErrAPI(t *testing.T) {
+	for _, code := range []int{402, 405, 418} {
+		err := fmt.Errorf("call: %w", ParseError(code, nil))
+		if !errors.Is(err, ErrAPI) || errors.Is(err, ErrValidation) || errors.Is(err, ErrServer) {
+			t.Errorf("status %d: want ErrAPI only, got %v", code, err)
+		}
+	}
+	if errors.Is(ParseError(404, nil), ErrAPI) {
+		t.Fatal("mapped status also matched ErrAPI")
+	}
+}
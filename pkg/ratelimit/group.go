@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRoute is the Stats key of a Group's default limiter.
+const DefaultRoute = "default"
+
+// Group routes requests to per-route limiters. Requests matching a route
+// take a token from that route's limiter and from the default limiter, if
+// any, so the default acts as an overall ceiling. Routes are matched in the
+// order they were added.
+type Group struct {
+	def    *Limiter
+	routes []route
+}
+
+type route struct {
+	pattern string
+	method  string
+	segs    []string
+	limiter *Limiter
+}
+
+// NewGroup returns a group with the given default limiter, which may be nil.
+func NewGroup(def *Limiter) *Group {
+	return &Group{def: def}
+}
+
+// Route adds a limiter for requests matching pattern: an optional method
+// followed by a path, where "*" matches one path segment, for example
+// "POST /v1/blueprints/*/entities/search" or "/v1/blueprints/*/entities/bulk".
+func (g *Group) Route(pattern string, l *Limiter) error {
+	r := route{pattern: pattern, limiter: l}
+	p := pattern
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		r.method, p = strings.ToUpper(method), strings.TrimSpace(path)
+	}
+	if !strings.HasPrefix(p, "/") {
+		return fmt.Errorf("ratelimit: route %q must contain an absolute path", pattern)
+	}
+	r.segs = strings.Split(strings.Trim(p, "/"), "/")
+	g.routes = append(g.routes, r)
+	return nil
+}
+
+// Wait blocks until req may be sent and returns the total time waited.
+func (g *Group) Wait(req *http.Request) (time.Duration, error) {
+	var total time.Duration
+	for _, l := range g.limiters(req) {
+		waited, err := l.Wait(req.Context())
+		total += waited
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Observe passes the response to the limiters req was routed to.
+func (g *Group) Observe(req *http.Request, resp *http.Response) {
+	for _, l := range g.limiters(req) {
+		l.Observe(resp)
+	}
+}
+
+// Stats returns counters keyed by route pattern, with the default limiter
+// under DefaultRoute.
+func (g *Group) Stats() map[string]Stats {
+	out := make(map[string]Stats, len(g.routes)+1)
+	if g.def != nil {
+		out[DefaultRoute] = g.def.Stats()
+	}
+	for _, r := range g.routes {
+		out[r.pattern] = r.limiter.Stats()
+	}
+	return out
+}
+
+func (g *Group) limiters(req *http.Request) []*Limiter {
+	var out []*Limiter
+	segs := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for _, r := range g.routes {
+		if r.matches(req.Method, segs) {
+			out = append(out, r.limiter)
+			break
+		}
+	}
+	if g.def != nil {
+		out = append(out, g.def)
+	}
+	return out
+}
+
+func (r route) matches(method string, segs []string) bool {
+	if r.method != "" && r.method != method {
+		return false
+	}
+	if len(segs) != len(r.segs) {
+		return false
+	}
+	for i, s := range r.segs {
+		if s != "*" && s != segs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+// Package ratelimit provides an adaptive token-bucket limiter used by the
+// client to stay under Port's API rate limits. Limiters slow down when the
+// server answers 429 or reports an exhausted quota in rate-limit headers, and
+// recover to the configured rate as requests succeed.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/port-experimental/port-go-sdk/pkg/httpx"
+)
+
+// Stats reports limiter counters.
+type Stats struct {
+	Requests  uint64        // tokens handed out
+	Delayed   uint64        // requests that had to wait
+	Throttled uint64        // 429 responses observed
+	TotalWait time.Duration // time spent waiting for tokens
+	MaxWait   time.Duration // longest single wait
+	Rate      float64       // current requests per second
+}
+
+// Limiter is a token bucket allowing rps requests per second with bursts of
+// up to burst requests. It is safe for concurrent use.
+type Limiter struct {
+	maxRate float64
+	burst   float64
+	now     func() time.Time
+
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	// last is when tokens were last refilled. It lies in the future while
+	// the limiter is paused, delaying every reservation until then.
+	last  time.Time
+	stats Stats
+}
+
+// New returns a full limiter. A burst below 1 is raised to 1. New returns an
+// error if rps is not positive, as a limiter without a rate would let every
+// request through once the burst is spent.
+func New(rps float64, burst int) (*Limiter, error) {
+	if !(rps > 0) {
+		return nil, fmt.Errorf("ratelimit: rps must be positive, got %v", rps)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	l := &Limiter{maxRate: rps, burst: float64(burst), rate: rps, tokens: float64(burst), now: time.Now}
+	l.last = l.now()
+	return l, nil
+}
+
+// Wait blocks until a request may be sent or ctx is done, and returns how
+// long it waited.
+func (l *Limiter) Wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := l.now()
+	l.refill(now)
+	l.tokens--
+	var wait time.Duration
+	if l.last.After(now) {
+		wait = l.last.Sub(now)
+	}
+	if l.tokens < 0 && l.rate > 0 {
+		wait += time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.stats.Requests++
+	if wait > 0 {
+		l.stats.Delayed++
+		l.stats.TotalWait += wait
+		if wait > l.stats.MaxWait {
+			l.stats.MaxWait = wait
+		}
+	}
+	l.mu.Unlock()
+	if wait <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return wait, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return 0, ctx.Err()
+	}
+}
+
+// Observe adapts the limiter to a response. A 429 halves the rate and pauses
+// until Retry-After (or one second); an exhausted quota reported by
+// X-RateLimit-Remaining/X-RateLimit-Reset (or the RateLimit-* equivalents)
+// pauses until the reset; any other response recovers a tenth of the
+// configured rate.
+func (l *Limiter) Observe(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.refill(now)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		l.stats.Throttled++
+		l.rate /= 2
+		if floor := l.maxRate / 16; l.rate < floor {
+			l.rate = floor
+		}
+		pause := time.Second
+		if d, ok := httpx.ParseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			pause = d
+		}
+		l.pause(now.Add(pause))
+		return
+	}
+	if until, ok := quotaReset(resp.Header, now); ok {
+		l.pause(until)
+	}
+	if l.rate < l.maxRate {
+		l.rate += l.maxRate / 10
+		if l.rate > l.maxRate {
+			l.rate = l.maxRate
+		}
+	}
+}
+
+// Stats returns a snapshot of the counters.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.stats
+	s.Rate = l.rate
+	return s
+}
+
+func (l *Limiter) refill(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// pause drains the bucket and stops refilling until t.
+func (l *Limiter) pause(t time.Time) {
+	if l.tokens > 0 {
+		l.tokens = 0
+	}
+	if t.After(l.last) {
+		l.last = t
+	}
+}
+
+// Quota is the rate-limit state reported by response headers.
+type Quota struct {
+	Limit     int // requests allowed per window; 0 when not reported
+	Remaining int
+	// Reset is when the window resets; zero when not reported.
+	Reset time.Time
+}
+
+// ParseQuota reads X-RateLimit-Limit, -Remaining and -Reset (or the
+// RateLimit-* equivalents). Reset values are seconds from now, or a Unix
+// timestamp for large values. It reports false when no remaining count is
+// present.
+func ParseQuota(h http.Header, now time.Time) (Quota, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		raw := h.Get(prefix + "Remaining")
+		if raw == "" {
+			continue
+		}
+		remaining, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return Quota{}, false
+		}
+		q := Quota{Remaining: int(remaining)}
+		if limit, err := strconv.ParseFloat(h.Get(prefix+"Limit"), 64); err == nil {
+			q.Limit = int(limit)
+		}
+		if reset, err := strconv.ParseInt(h.Get(prefix+"Reset"), 10, 64); err == nil && reset >= 0 {
+			if reset > 1e9 {
+				q.Reset = time.Unix(reset, 0)
+			} else {
+				q.Reset = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+		return q, true
+	}
+	return Quota{}, false
+}
+
+// quotaReset reports when the quota resets if the headers say it is used up.
+func quotaReset(h http.Header, now time.Time) (time.Time, bool) {
+	q, ok := ParseQuota(h, now)
+	if !ok || q.Remaining > 0 {
+		return time.Time{}, false
+	}
+	if q.Reset.IsZero() {
+		return now.Add(time.Second), true
+	}
+	return q.Reset, true
+}
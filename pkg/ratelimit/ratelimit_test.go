@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, rps float64, burst int) *Limiter {
+	t.Helper()
+	l, err := New(rps, burst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// reserve takes a token at the fake time and returns the wait it implies,
+// without sleeping.
+func reserve(t *testing.T, l *Limiter) time.Duration {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	before := l.Stats().TotalWait
+	if _, err := l.Wait(ctx); err != nil {
+		// A cancelled wait returns its token; take it again for bookkeeping.
+		l.mu.Lock()
+		l.tokens--
+		l.mu.Unlock()
+	}
+	return l.Stats().TotalWait - before
+}
+
+func fakeClock(l *Limiter) *time.Time {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	l.last = now
+	return &now
+}
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	l := mustNew(t, 10, 2)
+	now := fakeClock(l)
+	if reserve(t, l) != 0 || reserve(t, l) != 0 {
+		t.Fatal("burst should not wait")
+	}
+	if got := reserve(t, l); got != 100*time.Millisecond {
+		t.Fatalf("third request waited %v", got)
+	}
+	*now = now.Add(time.Second)
+	if got := reserve(t, l); got != 0 {
+		t.Fatalf("refilled bucket waited %v", got)
+	}
+	if s := l.Stats(); s.Requests != 4 || s.Delayed != 1 {
+		t.Fatalf("stats %+v", s)
+	}
+}
+
+func TestLimiterAdapts(t *testing.T) {
+	l := mustNew(t, 10, 5)
+	now := fakeClock(l)
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"2"}}}
+	l.Observe(resp)
+	if s := l.Stats(); s.Rate != 5 || s.Throttled != 1 {
+		t.Fatalf("after 429: %+v", s)
+	}
+	if got := reserve(t, l); got != 2*time.Second+200*time.Millisecond {
+		t.Fatalf("paused request waited %v", got)
+	}
+	*now = now.Add(10 * time.Second)
+	l.Observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+	if s := l.Stats(); s.Rate != 6 {
+		t.Fatalf("rate should recover, got %v", s.Rate)
+	}
+
+	l.Observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{
+		"X-Ratelimit-Remaining": {"0"},
+		"X-Ratelimit-Reset":     {"3"},
+	}})
+	if got := reserve(t, l); got < 3*time.Second {
+		t.Fatalf("exhausted quota should pause until reset, waited %v", got)
+	}
+}
+
+func TestGroupRoutes(t *testing.T) {
+	search, def := mustNew(t, 1, 1), mustNew(t, 100, 100)
+	g := NewGroup(def)
+	if err := g.Route("POST /v1/blueprints/*/entities/search", search); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Route("no-slash", search); err == nil {
+		t.Fatal("expected invalid route error")
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/blueprints/service/entities/search", nil)
+	if _, err := g.Wait(req); err != nil {
+		t.Fatal(err)
+	}
+	other := httptest.NewRequest(http.MethodGet, "/v1/blueprints/service/entities/search", nil)
+	if _, err := g.Wait(other); err != nil {
+		t.Fatal(err)
+	}
+	stats := g.Stats()
+	if stats["POST /v1/blueprints/*/entities/search"].Requests != 1 || stats[DefaultRoute].Requests != 2 {
+		t.Fatalf("stats %+v", stats)
+	}
+}
+
+func TestNewRejectsNonPositiveRate(t *testing.T) {
+	for _, rps := range []float64{0, -1, math.NaN()} {
+		if l, err := New(rps, 1); err == nil || l != nil {
+			t.Errorf("New(%v, 1) = %v, %v; want error", rps, l, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+)
+
+// Recorder is an in-memory Tracer and Meter for tests. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu           sync.Mutex
+	spans        []*RecordedSpan
+	measurements []Measurement
+}
+
+// RecordedSpan is a span captured by a Recorder.
+type RecordedSpan struct {
+	Name   string
+	Parent string // name of the parent span, if any
+	Attrs  map[string]any
+	Errors []error
+	Ended  bool
+
+	rec *Recorder
+}
+
+// Measurement is a counter increment or histogram value captured by a
+// Recorder.
+type Measurement struct {
+	Name  string
+	Value float64
+	Attrs map[string]any
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type spanKey struct{}
+
+// Start implements Tracer.
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	s := &RecordedSpan{Name: name, Attrs: map[string]any{}, rec: r}
+	if parent, ok := ctx.Value(spanKey{}).(*RecordedSpan); ok {
+		s.Parent = parent.Name
+	}
+	s.SetAttributes(attrs...)
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetAttributes implements Span.
+func (s *RecordedSpan) SetAttributes(attrs ...Attr) {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	for _, a := range attrs {
+		s.Attrs[a.Key] = a.Value
+	}
+}
+
+// RecordError implements Span.
+func (s *RecordedSpan) RecordError(err error) {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	s.Errors = append(s.Errors, err)
+}
+
+// End implements Span.
+func (s *RecordedSpan) End() {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	s.Ended = true
+}
+
+// Add implements Meter.
+func (r *Recorder) Add(_ context.Context, name string, n int64, attrs ...Attr) {
+	r.record(name, float64(n), attrs)
+}
+
+// Record implements Meter.
+func (r *Recorder) Record(_ context.Context, name string, v float64, attrs ...Attr) {
+	r.record(name, v, attrs)
+}
+
+func (r *Recorder) record(name string, v float64, attrs []Attr) {
+	m := Measurement{Name: name, Value: v, Attrs: map[string]any{}}
+	for _, a := range attrs {
+		m.Attrs[a.Key] = a.Value
+	}
+	r.mu.Lock()
+	r.measurements = append(r.measurements, m)
+	r.mu.Unlock()
+}
+
+// Spans returns copies of the recorded spans in start order.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]RecordedSpan, len(r.spans))
+	for i, s := range r.spans {
+		out[i] = *s
+		out[i].Attrs = copyAttrs(s.Attrs)
+		out[i].Errors = append([]error(nil), s.Errors...)
+		out[i].rec = nil
+	}
+	return out
+}
+
+// Measurements returns the measurements recorded for name.
+func (r *Recorder) Measurements(name string) []Measurement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Measurement
+	for _, m := range r.measurements {
+		if m.Name == name {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Sum returns the total of the measurements recorded for name.
+func (r *Recorder) Sum(name string) float64 {
+	var total float64
+	for _, m := range r.Measurements(name) {
+		total += m.Value
+	}
+	return total
+}
+
+func copyAttrs(in map[string]any) map[string]any {
+	out := make(map[string]any, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
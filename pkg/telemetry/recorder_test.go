@@ -0,0 +1,56 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecorderSpans(t *testing.T) {
+	rec := NewRecorder()
+	ctx, parent := rec.Start(context.Background(), "GET /v1/blueprints", String(AttrMethod, "GET"))
+	_, child := rec.Start(ctx, "POST /v1/auth/access_token")
+	child.SetAttributes(Int(AttrStatusCode, 200))
+	child.End()
+	parent.RecordError(errors.New("boom"))
+
+	spans := rec.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if p := spans[0]; p.Parent != "" || p.Ended || p.Attrs[AttrMethod] != "GET" || len(p.Errors) != 1 {
+		t.Fatalf("parent span %+v", p)
+	}
+	if c := spans[1]; c.Parent != "GET /v1/blueprints" || !c.Ended || c.Attrs[AttrStatusCode] != int64(200) {
+		t.Fatalf("child span %+v", c)
+	}
+
+	// Spans returns copies.
+	spans[0].Attrs[AttrMethod] = "PUT"
+	parent.End()
+	if s := rec.Spans()[0]; s.Attrs[AttrMethod] != "GET" || !s.Ended {
+		t.Fatalf("recorder state changed through a copy: %+v", s)
+	}
+}
+
+func TestRecorderMeasurements(t *testing.T) {
+	rec := NewRecorder()
+	ctx := context.Background()
+	rec.Add(ctx, MetricRequests, 1, String(AttrRoute, "/v1/blueprints"))
+	rec.Add(ctx, MetricRequests, 2)
+	rec.Record(ctx, MetricDuration, 0.25)
+
+	got := rec.Measurements(MetricRequests)
+	if len(got) != 2 || got[0].Value != 1 || got[0].Attrs[AttrRoute] != "/v1/blueprints" {
+		t.Fatalf("measurements %+v", got)
+	}
+	if sum := rec.Sum(MetricRequests); sum != 3 {
+		t.Fatalf("sum = %v", sum)
+	}
+	if sum := rec.Sum(MetricDuration); sum != 0.25 {
+		t.Fatalf("duration sum = %v", sum)
+	}
+	if got := rec.Measurements(MetricRetries); got != nil {
+		t.Fatalf("unexpected measurements %+v", got)
+	}
+}
@@ -0,0 +1,64 @@
+// Package telemetry defines the tracing and metrics hooks used by the client.
+// The interfaces follow the shape of the OpenTelemetry API; the adapter lives
+// in the separate github.com/port-experimental/port-go-sdk/telemetry/otel
+// module, so the SDK itself stays free of dependencies. Recorder is an in-memory
+// implementation for tests.
+package telemetry
+
+import "context"
+
+// Attribute keys set on spans and metrics. HTTP keys follow the OpenTelemetry
+// semantic conventions.
+const (
+	AttrMethod     = "http.request.method"
+	AttrRoute      = "http.route"
+	AttrStatusCode = "http.response.status_code"
+	AttrErrorType  = "error.type"
+	AttrRetryCount = "http.request.resend_count"
+	// AttrPrefix prefixes identifiers taken from the request path, such as
+	// "port.blueprint" and "port.entity".
+	AttrPrefix = "port."
+)
+
+// Metric names. Durations are recorded in seconds.
+const (
+	MetricRequests      = "port.client.requests"
+	MetricDuration      = "port.client.request.duration"
+	MetricRetries       = "port.client.retries"
+	MetricRateLimitWait = "port.client.ratelimit.wait"
+)
+
+// Attr is a key/value attribute. Values are string, int64, float64 or bool.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr { return Attr{Key: key, Value: value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr { return Attr{Key: key, Value: int64(value)} }
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx and returns a
+	// context carrying the new span.
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// Span is an operation started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	// RecordError records err and marks the span as failed.
+	RecordError(err error)
+	End()
+}
+
+// Meter records metrics.
+type Meter interface {
+	// Add increments the counter name by n.
+	Add(ctx context.Context, name string, n int64, attrs ...Attr)
+	// Record adds v to the histogram name.
+	Record(ctx context.Context, name string, v float64, attrs ...Attr)
+}
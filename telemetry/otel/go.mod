@@ -0,0 +1,22 @@
+module github.com/port-experimental/port-go-sdk/telemetry/otel
+
+go 1.22.0
+
+require (
+	github.com/port-experimental/port-go-sdk v0.2.4
+	go.opentelemetry.io/otel v1.34.0
+	go.opentelemetry.io/otel/metric v1.34.0
+	go.opentelemetry.io/otel/sdk v1.34.0
+	go.opentelemetry.io/otel/sdk/metric v1.34.0
+	go.opentelemetry.io/otel/trace v1.34.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+)
+
+replace github.com/port-experimental/port-go-sdk => ../..
@@ -0,0 +1,127 @@
+// Package otel adapts OpenTelemetry tracers and meters to the telemetry
+// interfaces used by the Port client:
+//
+//	cli, err := client.New(cfg,
+//		client.WithTracer(otel.NewTracer(otelapi.GetTracerProvider())),
+//		client.WithMeter(otel.NewMeter(otelapi.GetMeterProvider())))
+//
+// It is a separate module so the SDK itself stays free of dependencies.
+package otel
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/port-experimental/port-go-sdk/pkg/telemetry"
+	"github.com/port-experimental/port-go-sdk/pkg/version"
+)
+
+// ScopeName is the instrumentation scope of the tracers and meters created
+// by NewTracer and NewMeter.
+const ScopeName = "github.com/port-experimental/port-go-sdk"
+
+// NewTracer returns a telemetry.Tracer that starts client spans with a
+// tracer from tp.
+func NewTracer(tp trace.TracerProvider) telemetry.Tracer {
+	return tracer{tp.Tracer(ScopeName, trace.WithInstrumentationVersion(version.Version))}
+}
+
+type tracer struct{ t trace.Tracer }
+
+func (t tracer) Start(ctx context.Context, name string, attrs ...telemetry.Attr) (context.Context, telemetry.Span) {
+	ctx, s := t.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes(attrs)...))
+	return ctx, span{s}
+}
+
+type span struct{ s trace.Span }
+
+func (s span) SetAttributes(attrs ...telemetry.Attr) { s.s.SetAttributes(attributes(attrs)...) }
+
+func (s span) RecordError(err error) {
+	s.s.RecordError(err)
+	s.s.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() { s.s.End() }
+
+// secondMetrics are the histograms recorded in seconds.
+var secondMetrics = map[string]bool{telemetry.MetricDuration: true, telemetry.MetricRateLimitWait: true}
+
+// NewMeter returns a telemetry.Meter that records with a meter from mp.
+// Add updates an Int64Counter and Record a Float64Histogram, each created on
+// first use of a metric name. Errors creating instruments are passed to
+// otel.Handle.
+func NewMeter(mp metric.MeterProvider) telemetry.Meter {
+	return &meter{
+		m:          mp.Meter(ScopeName, metric.WithInstrumentationVersion(version.Version)),
+		counters:   map[string]metric.Int64Counter{},
+		histograms: map[string]metric.Float64Histogram{},
+	}
+}
+
+type meter struct {
+	m metric.Meter
+
+	mu         sync.Mutex
+	counters   map[string]metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+}
+
+func (m *meter) Add(ctx context.Context, name string, n int64, attrs ...telemetry.Attr) {
+	m.mu.Lock()
+	c, ok := m.counters[name]
+	if !ok {
+		var err error
+		if c, err = m.m.Int64Counter(name); err != nil {
+			otel.Handle(err)
+		}
+		m.counters[name] = c
+	}
+	m.mu.Unlock()
+	c.Add(ctx, n, metric.WithAttributes(attributes(attrs)...))
+}
+
+func (m *meter) Record(ctx context.Context, name string, v float64, attrs ...telemetry.Attr) {
+	m.mu.Lock()
+	h, ok := m.histograms[name]
+	if !ok {
+		var opts []metric.Float64HistogramOption
+		if secondMetrics[name] {
+			opts = append(opts, metric.WithUnit("s"))
+		}
+		var err error
+		if h, err = m.m.Float64Histogram(name, opts...); err != nil {
+			otel.Handle(err)
+		}
+		m.histograms[name] = h
+	}
+	m.mu.Unlock()
+	h.Record(ctx, v, metric.WithAttributes(attributes(attrs)...))
+}
+
+// attributes converts attributes; values of other types than those listed
+// on telemetry.Attr are dropped.
+func attributes(in []telemetry.Attr) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, 0, len(in))
+	for _, a := range in {
+		switch v := a.Value.(type) {
+		case string:
+			out = append(out, attribute.String(a.Key, v))
+		case int64:
+			out = append(out, attribute.Int64(a.Key, v))
+		case int:
+			out = append(out, attribute.Int(a.Key, v))
+		case float64:
+			out = append(out, attribute.Float64(a.Key, v))
+		case bool:
+			out = append(out, attribute.Bool(a.Key, v))
+		}
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/port-experimental/port-go-sdk/pkg/client"
+	"github.com/port-experimental/port-go-sdk/pkg/config"
+	"github.com/port-experimental/port-go-sdk/pkg/telemetry"
+)
+
+func TestClientWithOpenTelemetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+	spans := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	c, err := client.New(config.Config{APIToken: "token", BaseURL: srv.URL},
+		client.WithTracer(NewTracer(tp)), client.WithMeter(NewMeter(mp)))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if err := c.Do(context.Background(), http.MethodGet, "/v1/blueprints/service", nil, nil); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+
+	ended := spans.GetSpans()
+	if len(ended) != 1 {
+		t.Fatalf("expected one span, got %d", len(ended))
+	}
+	s := ended[0]
+	if s.Name != "GET /v1/blueprints/{blueprint}" || s.SpanKind != trace.SpanKindClient || s.InstrumentationScope.Name != ScopeName {
+		t.Fatalf("span %s kind %v scope %q", s.Name, s.SpanKind, s.InstrumentationScope.Name)
+	}
+	attrs := attribute.NewSet(s.Attributes...)
+	if v, _ := attrs.Value(telemetry.AttrStatusCode); v.AsInt64() != 200 {
+		t.Errorf("status attribute = %v", v.Emit())
+	}
+	if v, _ := attrs.Value("port.blueprint"); v.AsString() != "service" {
+		t.Errorf("blueprint attribute = %v", v.Emit())
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	metrics := map[string]metricdata.Metrics{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	requests, ok := metrics[telemetry.MetricRequests].Data.(metricdata.Sum[int64])
+	if !ok || len(requests.DataPoints) != 1 || requests.DataPoints[0].Value != 1 {
+		t.Fatalf("requests metric %+v", metrics[telemetry.MetricRequests])
+	}
+	duration := metrics[telemetry.MetricDuration]
+	if hist, ok := duration.Data.(metricdata.Histogram[float64]); !ok || duration.Unit != "s" || hist.DataPoints[0].Count != 1 {
+		t.Fatalf("duration metric %+v", duration)
+	}
+}
+
+func TestSpanRecordError(t *testing.T) {
+	spans := tracetest.NewInMemoryExporter()
+	tr := NewTracer(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)))
+	_, s := tr.Start(context.Background(), "op", telemetry.String("k", "v"), telemetry.Int("n", 3), telemetry.Attr{Key: "skip", Value: struct{}{}})
+	s.RecordError(errors.New("boom"))
+	s.End()
+
+	got := spans.GetSpans()[0]
+	if got.Status.Code != codes.Error || got.Status.Description != "boom" || len(got.Events) != 1 {
+		t.Fatalf("status %+v events %d", got.Status, len(got.Events))
+	}
+	want := []attribute.KeyValue{attribute.String("k", "v"), attribute.Int64("n", 3)}
+	if len(got.Attributes) != len(want) || got.Attributes[0] != want[0] || got.Attributes[1] != want[1] {
+		t.Fatalf("attributes %v", got.Attributes)
+	}
+}